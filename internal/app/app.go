@@ -1,33 +1,41 @@
 package app
 
 import (
+	"DelayedNotifier/internal/email"
 	"DelayedNotifier/internal/migrations"
-	"DelayedNotifier/internal/rabbitmq"
 	"DelayedNotifier/internal/repository"
+	"DelayedNotifier/internal/scheduler"
 	"DelayedNotifier/internal/service"
+	"DelayedNotifier/internal/storage"
 	"DelayedNotifier/internal/telegram"
 	"DelayedNotifier/internal/transport"
+	"DelayedNotifier/internal/webhook"
 	"DelayedNotifier/pkg/logger"
 	"DelayedNotifier/pkg/postgres"
 	redispkg "DelayedNotifier/pkg/redis"
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 
 	"github.com/wb-go/wbf/config"
+	"github.com/wb-go/wbf/dbpg"
+	wbfredis "github.com/wb-go/wbf/redis"
 	"go.uber.org/zap"
 )
 
 type App struct {
-	HiTalentServer   *transport.Server
-	cfg              *config.Config
-	ctx              context.Context
-	wg               sync.WaitGroup
-	cancel           context.CancelFunc
-	rabbitmqConsumer *rabbitmq.Consumer
-	rabbitmqClient   *rabbitmq.ClientRabbitMQ
+	HiTalentServer *transport.Server
+	cfg            *config.Config
+	ctx            context.Context
+	wg             sync.WaitGroup
+	cancel         context.CancelFunc
+	scheduler      scheduler.Scheduler
+	outboxRelay    *service.OutboxRelay
+	leaseRecovery  *service.LeaseRecovery
+	updates        *telegram.UpdateProcessor
 }
 
 func NewApp(cfg *config.Config, parentCtx context.Context) *App {
@@ -54,35 +62,100 @@ func NewApp(cfg *config.Config, parentCtx context.Context) *App {
 	}
 	logger.GetLoggerFromCtx(ctx).Info("Connected to Redis successfully")
 
-	repo := repository.NewNotificationRepository(ctx, db)
-	rabbitMQClient := rabbitmq.NewClientRabbitMQ(cfg, ctx)
-	err = rabbitMQClient.Init()
+	repo := repository.NewNotificationRepository(ctx, db, workerId(cfg))
+
+	telegramClient, err := telegram.NewClient(cfg, ctx)
 	if err != nil {
 		panic(err)
 	}
 
-	err = rabbitMQClient.SetupInfrastructure()
+	attachments, err := storage.NewLocalStorage(cfg)
 	if err != nil {
 		panic(err)
 	}
 
-	telegramClient, err := telegram.NewClient(cfg, ctx)
+	senders := service.NewSenderRegistry(
+		service.NewTelegramSender(telegramClient, telegram.NewRateLimiter(redisClient, cfg, ctx), attachments),
+		service.NewEmailSender(email.NewClient(cfg, ctx)),
+		service.NewWebhookSender(webhook.NewClient(cfg, ctx)),
+	)
+
+	srv := service.New(repo, senders, attachments, redisClient, ctx, cfg)
+
+	sched, err := newScheduler(cfg, ctx, db, redisClient, srv.ProcessNotification)
 	if err != nil {
 		panic(err)
 	}
 
-	producer := rabbitmq.NewProducer(rabbitMQClient, cfg)
-	srv := service.New(producer, repo, telegramClient, redisClient, ctx, cfg)
-	consumer := rabbitmq.NewConsumer(rabbitMQClient, cfg, srv.ProcessNotification)
+	outboxRelay := service.NewOutboxRelay(repo, sched, cfg)
 	server := transport.NewServer(ctx, cfg, srv)
 
+	var updates *telegram.UpdateProcessor
+	webhookURL := cfg.GetString("TELEGRAM_WEBHOOK_URL")
+	mode := cfg.GetString("TELEGRAM_UPDATES")
+	if mode == "" && webhookURL != "" {
+		mode = telegram.UpdatesWebhook
+	}
+	// Polling is opt-in: every replica polling the same bot would fight over
+	// getUpdates and drop a registered webhook.
+	switch mode {
+	case telegram.UpdatesPolling:
+		updates = telegram.NewUpdateProcessor(telegramClient, srv, ctx)
+	case telegram.UpdatesWebhook:
+		secret := cfg.GetString("TELEGRAM_WEBHOOK_SECRET")
+		if webhookURL == "" || secret == "" {
+			panic("TELEGRAM_WEBHOOK_URL and TELEGRAM_WEBHOOK_SECRET are required for webhook updates")
+		}
+		webhook := telegram.NewUpdateProcessor(telegramClient, srv, ctx)
+		if err := webhook.RegisterWebhook(webhookURL, secret); err != nil {
+			panic(err)
+		}
+		server.EnableTelegramWebhook(webhook, secret)
+	case "", telegram.UpdatesOff:
+		logger.GetLoggerFromCtx(ctx).Info("Telegram updates disabled, buttons will not respond")
+	default:
+		panic(fmt.Errorf("unknown telegram updates mode: %s", mode))
+	}
+
 	return &App{
-		HiTalentServer:   server,
-		cfg:              cfg,
-		ctx:              ctx,
-		cancel:           cancel,
-		rabbitmqConsumer: consumer,
-		rabbitmqClient:   rabbitMQClient,
+		HiTalentServer: server,
+		cfg:            cfg,
+		ctx:            ctx,
+		cancel:         cancel,
+		scheduler:      sched,
+		outboxRelay:    outboxRelay,
+		leaseRecovery:  service.NewLeaseRecovery(srv, cfg),
+		updates:        updates,
+	}
+}
+
+// workerId names this replica in notification events: WORKER_ID when set,
+// otherwise the host name and process id.
+func workerId(cfg *config.Config) string {
+	if id := cfg.GetString("WORKER_ID"); id != "" {
+		return id
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+func newScheduler(cfg *config.Config, ctx context.Context, db *dbpg.DB, redisClient *wbfredis.Client, handler scheduler.Handler) (scheduler.Scheduler, error) {
+	backend := cfg.GetString("SCHEDULER_BACKEND")
+	switch backend {
+	case "", scheduler.BackendRabbitMQ:
+		logger.GetLoggerFromCtx(ctx).Info("Using RabbitMQ scheduler")
+		return scheduler.NewRabbitMQScheduler(cfg, ctx, handler)
+	case scheduler.BackendPostgres:
+		logger.GetLoggerFromCtx(ctx).Info("Using Postgres scheduler")
+		return scheduler.NewPostgresScheduler(db, cfg, handler), nil
+	case scheduler.BackendRedis:
+		logger.GetLoggerFromCtx(ctx).Info("Using Redis scheduler")
+		return scheduler.NewRedisScheduler(redisClient, cfg, handler), nil
+	default:
+		return nil, fmt.Errorf("unknown scheduler backend: %s", backend)
 	}
 }
 
@@ -108,11 +181,37 @@ func (a *App) Run() error {
 	a.wg.Add(1)
 	go func() {
 		defer a.wg.Done()
-		logger.GetLoggerFromCtx(a.ctx).Info("Starting RabbitMQ consumer", zap.String("service", "rabbitmq_consumer"))
-		a.rabbitmqConsumer.Start(a.ctx)
-		logger.GetLoggerFromCtx(a.ctx).Info("Consumer stopped", zap.String("service", "rabbitmq_consumer"))
+		logger.GetLoggerFromCtx(a.ctx).Info("Starting scheduler consumer", zap.String("service", "scheduler"))
+		a.scheduler.Start(a.ctx)
+		logger.GetLoggerFromCtx(a.ctx).Info("Consumer stopped", zap.String("service", "scheduler"))
+	}()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		logger.GetLoggerFromCtx(a.ctx).Info("Starting outbox relay", zap.String("service", "outbox_relay"))
+		a.outboxRelay.Start(a.ctx)
+		logger.GetLoggerFromCtx(a.ctx).Info("Outbox relay stopped", zap.String("service", "outbox_relay"))
 	}()
 
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		logger.GetLoggerFromCtx(a.ctx).Info("Starting lease recovery", zap.String("service", "lease_recovery"))
+		a.leaseRecovery.Start(a.ctx)
+		logger.GetLoggerFromCtx(a.ctx).Info("Lease recovery stopped", zap.String("service", "lease_recovery"))
+	}()
+
+	if a.updates != nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			logger.GetLoggerFromCtx(a.ctx).Info("Starting Telegram update polling", zap.String("service", "telegram_updates"))
+			a.updates.Start(a.ctx)
+			logger.GetLoggerFromCtx(a.ctx).Info("Telegram update polling stopped", zap.String("service", "telegram_updates"))
+		}()
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
@@ -129,7 +228,7 @@ func (a *App) Run() error {
 	a.wg.Wait()
 
 	logger.GetLoggerFromCtx(a.ctx).Info("Closing connections")
-	a.rabbitmqClient.Close()
+	a.scheduler.Close()
 
 	logger.GetLoggerFromCtx(a.ctx).Info("Application stopped gracefully")
 	return nil
@@ -0,0 +1,151 @@
+package email
+
+import (
+	"DelayedNotifier/pkg/logger"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+	"go.uber.org/zap"
+)
+
+// defaultTimeout bounds a whole SMTP session, from dialing to QUIT, so a
+// stalled server cannot hold a delivery worker.
+const defaultTimeout = 30 * time.Second
+
+type Client struct {
+	addr    string
+	host    string
+	from    string
+	auth    smtp.Auth
+	timeout time.Duration
+	ctx     context.Context
+}
+
+func NewClient(cfg *config.Config, ctx context.Context) *Client {
+	host := cfg.GetString("SMTP_HOST")
+
+	var auth smtp.Auth
+	if username := cfg.GetString("SMTP_USERNAME"); username != "" {
+		auth = smtp.PlainAuth("", username, cfg.GetString("SMTP_PASSWORD"), host)
+	}
+
+	timeout := time.Duration(cfg.GetInt("SMTP_TIMEOUT_MS")) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Client{
+		addr:    fmt.Sprintf("%s:%d", host, cfg.GetInt("SMTP_PORT")),
+		host:    host,
+		from:    cfg.GetString("SMTP_FROM"),
+		auth:    auth,
+		timeout: timeout,
+		ctx:     ctx,
+	}
+}
+
+func (c *Client) SendEmail(to string, subject string, body string) error {
+	msg, err := c.buildMessage(to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	if err := c.send(to, msg); err != nil {
+		return err
+	}
+
+	logger.GetLoggerFromCtx(c.ctx).Info("Email sent successfully",
+		zap.String("to", to))
+
+	return nil
+}
+
+// send delivers msg to a single recipient like smtp.SendMail, but gives up
+// once the session takes longer than the client timeout.
+func (c *Client) send(to string, msg []byte) error {
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(c.ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	host := c.host
+	if host == "" {
+		host, _, _ = net.SplitHostPort(c.addr)
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if c.auth != nil {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(c.auth); err != nil {
+				return err
+			}
+		}
+	}
+	if err := client.Mail(c.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (c *Client) buildMessage(to string, subject string, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", c.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return nil, fmt.Errorf("failed to encode email body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode email body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,152 @@
+package email
+
+import (
+	"DelayedNotifier/pkg/logger"
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTPServer accepts a single SMTP session and reports the envelope
+// and message it received.
+func startFakeSMTPServer(t *testing.T, rejectRcpt bool) (string, <-chan receivedMail) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan receivedMail, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		var m receivedMail
+		reply("220 localhost fake SMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				m.from = strings.Trim(strings.TrimSpace(line)[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				if rejectRcpt {
+					reply("550 mailbox unavailable")
+					continue
+				}
+				m.to = append(m.to, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				m.data = data.String()
+				reply("250 OK")
+				received <- m
+			case cmd == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), received
+}
+
+func newTestClient(t *testing.T, addr string) *Client {
+	ctx, err := logger.New(context.Background())
+	require.NoError(t, err)
+	return &Client{addr: addr, from: "notifier@example.com", ctx: ctx}
+}
+
+func TestClient_SendEmailSuccess(t *testing.T) {
+	addr, received := startFakeSMTPServer(t, false)
+	client := newTestClient(t, addr)
+
+	err := client.SendEmail("user@example.com", "Напоминание", "Созвон в 10:00")
+	require.NoError(t, err)
+
+	m := <-received
+	require.Equal(t, "notifier@example.com", m.from)
+	require.Equal(t, []string{"user@example.com"}, m.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(m.data))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, "Напоминание", subject)
+
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	require.NoError(t, err)
+	require.Equal(t, "Созвон в 10:00", strings.TrimRight(string(body), "\r\n"))
+}
+
+func TestClient_SendEmailRejectedRecipient(t *testing.T) {
+	addr, _ := startFakeSMTPServer(t, true)
+	client := newTestClient(t, addr)
+
+	err := client.SendEmail("nobody@example.com", "Subject", "Body")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "550")
+}
+
+func TestClient_SendEmailStalledServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	// Accept the connection but never send the greeting.
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+	}()
+
+	client := newTestClient(t, ln.Addr().String())
+	client.timeout = 200 * time.Millisecond
+
+	start := time.Now()
+	err = client.SendEmail("user@example.com", "Subject", "Body")
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
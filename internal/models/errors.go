@@ -0,0 +1,30 @@
+package models
+
+import "errors"
+
+// ValidationError is an error caused by the request itself rather than by a
+// failure of the service or its dependencies; the API answers it with 400.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Invalid marks err as a ValidationError. It returns nil for a nil err.
+func Invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ValidationError{Err: err}
+}
+
+func IsInvalid(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Kinds of notification events. Most name the status the notification moved
+// to; the rest say why a notification went back to "created".
+const (
+	EventCreated        = "created"
+	EventRescheduled    = "rescheduled"
+	EventSnoozed        = "snoozed"
+	EventSending        = "sending"
+	EventRetryScheduled = "retry_scheduled"
+	EventSent           = "sent"
+	EventFailed         = "failed"
+	EventNextOccurrence = "next_occurrence"
+	EventCancelled      = "cancelled"
+	EventRetracted      = "retracted"
+)
+
+// NotificationEvent records one status transition of a notification: the
+// status it moved to, the attempt and scheduled send time at that moment,
+// the worker that made the change and, for failures, the error.
+type NotificationEvent struct {
+	Id             int64     `json:"id"`
+	NotificationId string    `json:"notification_id"`
+	Event          string    `json:"event"`
+	Status         Status    `json:"status"`
+	Attempt        int       `json:"attempt"`
+	SendAt         time.Time `json:"send_at"`
+	WorkerId       string    `json:"worker_id,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
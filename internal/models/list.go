@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// NotificationFilter holds the query parameters of a notification listing as
+// sent by clients. Status may list several statuses separated by commas and
+// Sort is "time" or "-time".
+type NotificationFilter struct {
+	Status  string `form:"status"`
+	ChatId  int64  `form:"chat_id"`
+	Channel string `form:"channel"`
+	From    string `form:"from"`
+	To      string `form:"to"`
+	Sort    string `form:"sort"`
+	Limit   int    `form:"limit"`
+	Cursor  string `form:"cursor"`
+}
+
+// NotificationQuery is a validated NotificationFilter. Zero fields do not
+// filter. Results are ordered by send time and id, and continue after
+// AfterSendAt and AfterId when AfterId is set.
+type NotificationQuery struct {
+	Statuses    []Status
+	ChatId      int64
+	Channel     string
+	From        time.Time
+	To          time.Time
+	Desc        bool
+	Limit       int
+	AfterSendAt time.Time
+	AfterId     string
+}
+
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	Total         int             `json:"total"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+}
@@ -1,9 +1,97 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+)
+
+const (
+	FormatPlain      = "plain"
+	FormatMarkdownV2 = "markdown_v2"
+	FormatHTML       = "html"
+)
+
+const (
+	AttachmentPhoto    = "photo"
+	AttachmentDocument = "document"
+	AttachmentLocation = "location"
+)
+
+// Attachment is a photo, document or location sent along with a notification.
+// Files are either fetched by Telegram from URL or uploaded with the
+// notification, in which case FileKey locates them in attachment storage.
+type Attachment struct {
+	Type      string  `json:"type"`
+	URL       string  `json:"url,omitempty"`
+	FileKey   string  `json:"file_key,omitempty"`
+	FileName  string  `json:"file_name,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+}
+
+// Actions of callback buttons.
+const (
+	ActionAck    = "ack"
+	ActionSnooze = "snooze"
+	ActionCancel = "cancel"
+)
+
+// Button is an inline keyboard button shown under a Telegram notification: a
+// link when URL is set, otherwise a callback button performing Action. Snooze
+// is the delay of the snooze action, such as "10m".
+type Button struct {
+	Text   string `json:"text"`
+	URL    string `json:"url,omitempty"`
+	Action string `json:"action,omitempty"`
+	Snooze string `json:"snooze,omitempty"`
+}
+
+// FallbackTarget is one step of a fallback chain. Empty ChatId or Recipient
+// are inherited from the notification itself.
+type FallbackTarget struct {
+	Channel   string `json:"channel"`
+	ChatId    int64  `json:"chat_id,omitempty"`
+	Recipient string `json:"recipient,omitempty"`
+}
+
 type Notification struct {
-	Id      string `json:"id"`
-	Message string `json:"message"`
-	Time    string `json:"time"`
-	Status  string `json:"status"`
-	ChatId  int64  `json:"chat_id"`
+	Id              string            `json:"id"`
+	Message         string            `json:"message"`
+	Time            string            `json:"time"`
+	SendAt          time.Time         `json:"send_at"`
+	Status          Status            `json:"status"`
+	ChatId          int64             `json:"chat_id"`
+	Channel         string            `json:"channel"`
+	Recipient       string            `json:"recipient,omitempty"`
+	Subject         string            `json:"subject,omitempty"`
+	Format          string            `json:"format,omitempty"`
+	Buttons         [][]Button        `json:"buttons,omitempty"`
+	Attachment      *Attachment       `json:"attachment,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Payload         json.RawMessage   `json:"payload,omitempty"`
+	Fallback        []FallbackTarget  `json:"fallback,omitempty"`
+	DeliveredVia    string            `json:"delivered_via,omitempty"`
+	DeliveredChatId int64             `json:"delivered_chat_id,omitempty"`
+	AcknowledgedAt  string            `json:"acknowledged_at,omitempty"`
+	Split           bool              `json:"split,omitempty"`
+	MessageIds      []int             `json:"message_ids,omitempty"`
+	Attempts        int               `json:"attempts"`
+	MaxAttempts     int               `json:"max_attempts,omitempty"`
+	Backoff         string            `json:"backoff,omitempty"`
+	MaxAge          string            `json:"max_age,omitempty"`
+	Version         int               `json:"version"`
+	Schedule        string            `json:"schedule,omitempty"`
+	EndAt           string            `json:"end_at,omitempty"`
+	MaxOccurrences  int               `json:"max_occurrences,omitempty"`
+	Occurrences     int               `json:"occurrences,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	SentAt          *time.Time        `json:"sent_at,omitempty"`
+	LastError       string            `json:"last_error,omitempty"`
+	LeaseUntil      *time.Time        `json:"lease_until,omitempty"`
 }
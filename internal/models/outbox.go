@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type OutboxMessage struct {
+	Id             int64     `json:"id"`
+	NotificationId string    `json:"notification_id"`
+	Payload        []byte    `json:"payload"`
+	RoutingKey     string    `json:"routing_key"`
+	SendAt         time.Time `json:"send_at"`
+}
+
+// Occurrence describes how the current firing of a recurring notification
+// ends. NextTime and Outbox schedule the following firing; NextTime is empty
+// when the series is over.
+type Occurrence struct {
+	NextTime string
+	Outbox   *OutboxMessage
+}
@@ -0,0 +1,50 @@
+package models
+
+import "fmt"
+
+// Status is the stage of its life a notification is at.
+type Status string
+
+const (
+	StatusCreated   Status = "created"
+	StatusSending   Status = "sending"
+	StatusSent      Status = "sent"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+	StatusRetracted Status = "retracted"
+)
+
+// transitions lists the statuses a notification may move to from each
+// status. created → created is a reschedule; returning to created from
+// sending, sent or failed is a retry, a snooze or the next occurrence of a
+// recurring notification. Nothing leaves cancelled or retracted, and sending
+// is only entered from created, so a notification is claimed for delivery
+// once per schedule.
+var transitions = map[Status][]Status{
+	StatusCreated:   {StatusCreated, StatusSending, StatusCancelled},
+	StatusSending:   {StatusSent, StatusFailed, StatusCreated},
+	StatusSent:      {StatusCreated, StatusRetracted},
+	StatusFailed:    {StatusCreated},
+	StatusCancelled: nil,
+	StatusRetracted: nil,
+}
+
+// ParseStatus returns the Status named s.
+func ParseStatus(s string) (Status, error) {
+	status := Status(s)
+	if _, ok := transitions[status]; !ok {
+		return "", fmt.Errorf("unknown status %q", s)
+	}
+	return status, nil
+}
+
+// CanTransition reports whether a notification in status from may move to
+// status to.
+func CanTransition(from, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
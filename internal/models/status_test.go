@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := []struct{ from, to Status }{
+		{StatusCreated, StatusSending},
+		{StatusCreated, StatusCreated},
+		{StatusCreated, StatusCancelled},
+		{StatusSending, StatusSent},
+		{StatusSending, StatusFailed},
+		{StatusSending, StatusCreated},
+		{StatusSent, StatusCreated},
+		{StatusSent, StatusRetracted},
+		{StatusFailed, StatusCreated},
+	}
+	for _, tr := range allowed {
+		require.True(t, CanTransition(tr.from, tr.to), "%s -> %s", tr.from, tr.to)
+	}
+
+	forbidden := []struct{ from, to Status }{
+		{StatusSending, StatusSending},
+		{StatusSent, StatusSending},
+		{StatusFailed, StatusSending},
+		{StatusCreated, StatusSent},
+		{StatusCancelled, StatusCreated},
+		{StatusRetracted, StatusSent},
+		{StatusSent, StatusCancelled},
+	}
+	for _, tr := range forbidden {
+		require.False(t, CanTransition(tr.from, tr.to), "%s -> %s", tr.from, tr.to)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	status, err := ParseStatus("sent")
+	require.NoError(t, err)
+	require.Equal(t, StatusSent, status)
+
+	_, err = ParseStatus("delivered")
+	require.EqualError(t, err, `unknown status "delivered"`)
+}
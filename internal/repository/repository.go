@@ -5,40 +5,231 @@ import (
 	"DelayedNotifier/pkg/logger"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/wb-go/wbf/dbpg"
 	"go.uber.org/zap"
 )
 
+const notificationColumns = `id, message, time, send_at, status, chat_id, channel, recipient, subject, format, buttons, attachment, headers, payload, fallback, delivered_via, delivered_chat_id, acknowledged_at, split, message_ids, attempts, max_attempts, backoff, max_age, version, schedule, end_at, max_occurrences, occurrences, created_at, sent_at, last_error, lease_until`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	var buttons, attachment, headers, payload, fallback, messageIds []byte
+	var sentAt, leaseUntil sql.NullTime
+	nf := &models.Notification{}
+	err := row.Scan(
+		&nf.Id,
+		&nf.Message,
+		&nf.Time,
+		&nf.SendAt,
+		&nf.Status,
+		&nf.ChatId,
+		&nf.Channel,
+		&nf.Recipient,
+		&nf.Subject,
+		&nf.Format,
+		&buttons,
+		&attachment,
+		&headers,
+		&payload,
+		&fallback,
+		&nf.DeliveredVia,
+		&nf.DeliveredChatId,
+		&nf.AcknowledgedAt,
+		&nf.Split,
+		&messageIds,
+		&nf.Attempts,
+		&nf.MaxAttempts,
+		&nf.Backoff,
+		&nf.MaxAge,
+		&nf.Version,
+		&nf.Schedule,
+		&nf.EndAt,
+		&nf.MaxOccurrences,
+		&nf.Occurrences,
+		&nf.CreatedAt,
+		&sentAt,
+		&nf.LastError,
+		&leaseUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if sentAt.Valid {
+		nf.SentAt = &sentAt.Time
+	}
+	if leaseUntil.Valid {
+		nf.LeaseUntil = &leaseUntil.Time
+	}
+	if err := json.Unmarshal(buttons, &nf.Buttons); err != nil {
+		return nil, fmt.Errorf("failed to decode buttons: %w", err)
+	}
+	if len(attachment) > 0 {
+		if err := json.Unmarshal(attachment, &nf.Attachment); err != nil {
+			return nil, fmt.Errorf("failed to decode attachment: %w", err)
+		}
+	}
+	if err := json.Unmarshal(headers, &nf.Headers); err != nil {
+		return nil, fmt.Errorf("failed to decode headers: %w", err)
+	}
+	if len(payload) > 0 {
+		nf.Payload = payload
+	}
+	if err := json.Unmarshal(fallback, &nf.Fallback); err != nil {
+		return nil, fmt.Errorf("failed to decode fallback: %w", err)
+	}
+	if err := json.Unmarshal(messageIds, &nf.MessageIds); err != nil {
+		return nil, fmt.Errorf("failed to decode message ids: %w", err)
+	}
+	return nf, nil
+}
+
+func encodeHeaders(headers map[string]string) (string, error) {
+	if headers == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(headers)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode headers: %w", err)
+	}
+	return string(data), nil
+}
+
+func encodeFallback(fallback []models.FallbackTarget) (string, error) {
+	if fallback == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(fallback)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode fallback: %w", err)
+	}
+	return string(data), nil
+}
+
+func encodeButtons(buttons [][]models.Button) (string, error) {
+	if buttons == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(buttons)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode buttons: %w", err)
+	}
+	return string(data), nil
+}
+
+func encodeAttachment(attachment *models.Attachment) (any, error) {
+	if attachment == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(attachment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attachment: %w", err)
+	}
+	return string(data), nil
+}
+
+func encodeMessageIds(ids []int) (string, error) {
+	if ids == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode message ids: %w", err)
+	}
+	return string(data), nil
+}
+
+func encodePayload(payload json.RawMessage) any {
+	if len(payload) == 0 {
+		return nil
+	}
+	return string(payload)
+}
+
 type NotificationRepository struct {
-	ctx context.Context
-	db  *dbpg.DB
+	ctx      context.Context
+	db       *dbpg.DB
+	workerId string
 }
 
-func NewNotificationRepository(ctx context.Context, db *dbpg.DB) *NotificationRepository {
+// NewNotificationRepository returns a repository that signs the events it
+// records with workerId.
+func NewNotificationRepository(ctx context.Context, db *dbpg.DB, workerId string) *NotificationRepository {
 	return &NotificationRepository{
-		ctx: ctx,
-		db:  db,
+		ctx:      ctx,
+		db:       db,
+		workerId: workerId,
 	}
 }
 
-func (r *NotificationRepository) CreateNotification(notification *models.Notification) error {
+func (r *NotificationRepository) CreateNotification(notification *models.Notification, outbox *models.OutboxMessage) error {
 	query := `
-		INSERT INTO notifications (id, message, time, status, chat_id)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO notifications (id, message, time, send_at, status, chat_id, channel, recipient, subject, format, buttons, attachment, headers, payload, fallback, split, max_attempts, backoff, max_age, version, schedule, end_at, max_occurrences, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 	`
 
-	_, err := r.db.ExecContext(
-		r.ctx,
-		query,
-		notification.Id,
-		notification.Message,
-		notification.Time,
-		notification.Status,
-		notification.ChatId,
-	)
+	buttons, err := encodeButtons(notification.Buttons)
+	if err != nil {
+		return err
+	}
+	attachment, err := encodeAttachment(notification.Attachment)
+	if err != nil {
+		return err
+	}
+	headers, err := encodeHeaders(notification.Headers)
+	if err != nil {
+		return err
+	}
+	fallback, err := encodeFallback(notification.Fallback)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.WithTx(r.ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			r.ctx,
+			query,
+			notification.Id,
+			notification.Message,
+			notification.Time,
+			outbox.SendAt,
+			notification.Status,
+			notification.ChatId,
+			notification.Channel,
+			notification.Recipient,
+			notification.Subject,
+			notification.Format,
+			buttons,
+			attachment,
+			headers,
+			encodePayload(notification.Payload),
+			fallback,
+			notification.Split,
+			notification.MaxAttempts,
+			notification.Backoff,
+			notification.MaxAge,
+			notification.Version,
+			notification.Schedule,
+			notification.EndAt,
+			notification.MaxOccurrences,
+			notification.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if err := r.insertEvent(tx, notification.Id, models.EventCreated, ""); err != nil {
+			return err
+		}
+		return r.insertOutbox(tx, notification, outbox)
+	})
 	if err != nil {
 		logger.GetLoggerFromCtx(r.ctx).Error("Failed to create notification in DB",
 			zap.Error(err),
@@ -51,14 +242,219 @@ func (r *NotificationRepository) CreateNotification(notification *models.Notific
 	return nil
 }
 
-func (r *NotificationRepository) GetNotificationStatus(id string) (string, error) {
+func (r *NotificationRepository) insertOutbox(tx *sql.Tx, notification *models.Notification, outbox *models.OutboxMessage) error {
+	query := `
+		INSERT INTO notification_outbox (notification_id, payload, routing_key, send_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	outbox.NotificationId = notification.Id
+	outbox.Payload = payload
+
+	return tx.QueryRowContext(r.ctx, query, outbox.NotificationId, outbox.Payload, outbox.RoutingKey, outbox.SendAt).Scan(&outbox.Id)
+}
+
+// insertEvent records that notification id has just changed, copying its new
+// status, attempt and send time from the row as updated in tx.
+func (r *NotificationRepository) insertEvent(tx *sql.Tx, id, event, errText string) error {
+	query := `
+		INSERT INTO notification_events (notification_id, event, status, attempt, send_at, worker_id, error)
+		SELECT id, $2, status, attempts, send_at, $3, $4
+		FROM notifications
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(r.ctx, query, id, event, r.workerId, errText); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", event, err)
+	}
+	return nil
+}
+
+// updateWithEvent runs an UPDATE of notification id and, if it changed the
+// row, records event in the same transaction. It reports whether the row
+// was changed.
+func (r *NotificationRepository) updateWithEvent(id, event, errText, query string, args ...any) (bool, error) {
+	return r.updateWithEventThen(id, event, errText, nil, query, args...)
+}
+
+// updateWithEventThen is updateWithEvent that also runs then, when not nil,
+// in the same transaction after the row was changed.
+func (r *NotificationRepository) updateWithEventThen(id, event, errText string, then func(tx *sql.Tx) error, query string, args ...any) (bool, error) {
+	var updated bool
+	err := r.db.WithTx(r.ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(r.ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return nil
+		}
+		updated = true
+		if err := r.insertEvent(tx, id, event, errText); err != nil {
+			return err
+		}
+		if then != nil {
+			return then(tx)
+		}
+		return nil
+	})
+	return updated, err
+}
+
+// finishOccurrence returns a step that records how the current firing of
+// recurring notification id ended in status and, unless the series is over,
+// queues the next one. It returns nil for a one-off notification.
+func (r *NotificationRepository) finishOccurrence(id string, status models.Status, occurrence *models.Occurrence) func(tx *sql.Tx) error {
+	if occurrence == nil {
+		return nil
+	}
+	return func(tx *sql.Tx) error {
+		if err := r.recordOccurrence(tx, id, status); err != nil {
+			return err
+		}
+		if occurrence.NextTime == "" {
+			return nil
+		}
+		return r.scheduleNextOccurrence(tx, id, status, occurrence)
+	}
+}
+
+func (r *NotificationRepository) recordOccurrence(tx *sql.Tx, id string, status models.Status) error {
+	updateQuery := `
+  		UPDATE notifications
+  		SET occurrences = occurrences + 1
+  		WHERE id = $1
+  		RETURNING occurrences, time
+  	`
+	insertQuery := `
+		INSERT INTO notification_occurrences (notification_id, occurrence, scheduled_time, status)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	var (
+		occurrence    int
+		scheduledTime string
+	)
+	if err := tx.QueryRowContext(r.ctx, updateQuery, id).Scan(&occurrence, &scheduledTime); err != nil {
+		return fmt.Errorf("failed to record occurrence: %w", err)
+	}
+	if _, err := tx.ExecContext(r.ctx, insertQuery, id, occurrence, scheduledTime, status); err != nil {
+		return fmt.Errorf("failed to record occurrence: %w", err)
+	}
+	return nil
+}
+
+// scheduleNextOccurrence moves recurring notification id, whose current
+// firing just ended in status from, to the next firing and queues it.
+func (r *NotificationRepository) scheduleNextOccurrence(tx *sql.Tx, id string, from models.Status, occurrence *models.Occurrence) error {
+	next, err := newTransition(from, models.StatusCreated)
+	if err != nil {
+		return err
+	}
+	query := `
+  		UPDATE notifications
+  		SET time = $2,
+  		    send_at = $3,
+  		    status = $4,
+  		    delivered_via = '',
+  		    delivered_chat_id = 0,
+  		    attempts = 0,
+  		    message_ids = '[]',
+  		    version = version + 1
+  		WHERE id = $1 AND status = $5
+  		RETURNING ` + notificationColumns
+
+	nf, err := scanNotification(tx.QueryRowContext(r.ctx, query,
+		id, occurrence.NextTime, occurrence.Outbox.SendAt, next.to, next.from))
+	if err != nil {
+		return fmt.Errorf("failed to schedule next occurrence: %w", err)
+	}
+	if err := r.insertEvent(tx, id, models.EventNextOccurrence, ""); err != nil {
+		return err
+	}
+	if err := r.insertOutbox(tx, nf, occurrence.Outbox); err != nil {
+		return fmt.Errorf("failed to schedule next occurrence: %w", err)
+	}
+
+	logger.GetLoggerFromCtx(r.ctx).Info("Next occurrence scheduled in DB",
+		zap.String("notification_id", id),
+		zap.String("time", occurrence.NextTime),
+		zap.Int("version", nf.Version))
+	return nil
+}
+
+// ProcessOutbox publishes up to limit outbox rows in order and deletes the
+// published ones, so the table only holds messages still waiting for RabbitMQ.
+func (r *NotificationRepository) ProcessOutbox(limit int, publish func(*models.OutboxMessage) error) (int, error) {
+	selectQuery := `
+		SELECT id, notification_id, payload, routing_key, send_at
+		FROM notification_outbox
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+	deleteQuery := `
+		DELETE FROM notification_outbox
+		WHERE id = $1
+	`
+
+	processed := 0
+	err := r.db.WithTx(r.ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(r.ctx, selectQuery, limit)
+		if err != nil {
+			return err
+		}
+
+		var messages []*models.OutboxMessage
+		for rows.Next() {
+			msg := &models.OutboxMessage{}
+			if err := rows.Scan(&msg.Id, &msg.NotificationId, &msg.Payload, &msg.RoutingKey, &msg.SendAt); err != nil {
+				rows.Close()
+				return err
+			}
+			messages = append(messages, msg)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, msg := range messages {
+			if err := publish(msg); err != nil {
+				logger.GetLoggerFromCtx(r.ctx).Warn("Failed to publish outbox message, will retry",
+					zap.Error(err),
+					zap.Int64("outbox_id", msg.Id),
+					zap.String("notification_id", msg.NotificationId))
+				break
+			}
+			if _, err := tx.ExecContext(r.ctx, deleteQuery, msg.Id); err != nil {
+				return err
+			}
+			processed++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to process outbox",
+			zap.Error(err))
+		return 0, fmt.Errorf("failed to process outbox: %w", err)
+	}
+
+	return processed, nil
+}
+
+func (r *NotificationRepository) GetNotificationStatus(id string) (models.Status, error) {
 	query := `
   		SELECT status
   		FROM notifications
   		WHERE id = $1
   	`
 
-	var status string
+	var status models.Status
 
 	err := r.db.QueryRowContext(r.ctx, query, id).Scan(
 		&status,
@@ -77,69 +473,583 @@ func (r *NotificationRepository) GetNotificationStatus(id string) (string, error
 	return status, nil
 }
 
-func (r *NotificationRepository) UpdateNotificationStatus(id string, status string) error {
+// MarkNotificationFailed ends the delivery of notification id at version with
+// lastError. For a recurring notification occurrence is recorded and the
+// next firing queued in the same transaction.
+func (r *NotificationRepository) MarkNotificationFailed(id string, version int, lastError string, occurrence *models.Occurrence) error {
 	query := `
   		UPDATE notifications
-  		SET status = $1
-  		WHERE id = $2
+  		SET status = $3,
+  		    last_error = $5
+  		WHERE id = $1 AND version = $2 AND status = $4
   	`
-	_, err := r.db.ExecContext(r.ctx, query, status, id)
+	updated, err := r.updateWithEventThen(id, models.EventFailed, lastError,
+		r.finishOccurrence(id, failedTransition.to, occurrence), query,
+		id, version, failedTransition.to, failedTransition.from, lastError)
 	if err != nil {
-		logger.GetLoggerFromCtx(r.ctx).Error("Failed to update notification status",
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to mark notification as failed",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return fmt.Errorf("failed to mark notification as failed: %w", err)
+	}
+	if !updated {
+		return fmt.Errorf("notification %s is no longer being sent", id)
+	}
+
+	logger.GetLoggerFromCtx(r.ctx).Debug("Notification marked as failed",
+		zap.String("notification_id", id))
+	return nil
+}
+
+// MarkNotificationSent records the delivery of notification id at version.
+// For a recurring notification occurrence is recorded and the next firing
+// queued in the same transaction.
+func (r *NotificationRepository) MarkNotificationSent(id string, version int, channel string, chatId int64, messageIds []int, occurrence *models.Occurrence) error {
+	query := `
+  		UPDATE notifications
+  		SET status = $3,
+  		    delivered_via = $5,
+  		    delivered_chat_id = $7,
+  		    message_ids = $6,
+  		    sent_at = now()
+  		WHERE id = $1 AND version = $2 AND status = $4
+  	`
+	ids, err := encodeMessageIds(messageIds)
+	if err != nil {
+		return err
+	}
+
+	updated, err := r.updateWithEventThen(id, models.EventSent, "",
+		r.finishOccurrence(id, sentTransition.to, occurrence), query,
+		id, version, sentTransition.to, sentTransition.from, channel, ids, chatId)
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to mark notification as sent",
 			zap.Error(err),
 			zap.String("notification_id", id),
-			zap.String("status", status))
-		return fmt.Errorf("failed to update notification status: %w", err)
+			zap.String("channel", channel))
+		return fmt.Errorf("failed to mark notification as sent: %w", err)
+	}
+	if !updated {
+		return fmt.Errorf("notification %s is no longer being sent", id)
 	}
 
-	logger.GetLoggerFromCtx(r.ctx).Debug("Notification status updated",
+	logger.GetLoggerFromCtx(r.ctx).Debug("Notification marked as sent",
 		zap.String("notification_id", id),
-		zap.String("status", status))
+		zap.String("channel", channel))
 	return nil
 }
 
-func (r *NotificationRepository) DeleteNotification(id string) error {
+// SaveMessageIds records the parts of a split message delivered so far, so a
+// retry resumes after them instead of sending them again.
+func (r *NotificationRepository) SaveMessageIds(id string, version int, messageIds []int) error {
 	query := `
-  		DELETE FROM notifications
-  		WHERE id = $1
+  		UPDATE notifications
+  		SET message_ids = $4
+  		WHERE id = $1 AND version = $2 AND status = $3
+  	`
+	ids, err := encodeMessageIds(messageIds)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(r.ctx, query, id, version, models.StatusSending, ids)
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to save message ids",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return fmt.Errorf("failed to save message ids: %w", err)
+	}
+	return nil
+}
+
+// UpdateSentMessage stores the new text of a delivered notification after
+// its message was edited.
+func (r *NotificationRepository) UpdateSentMessage(id string, message string, format string) (*models.Notification, error) {
+	query := `
+  		UPDATE notifications
+  		SET message = $2,
+  		    format = $3
+  		WHERE id = $1 AND status = $4
+  		RETURNING ` + notificationColumns
+
+	nf, err := scanNotification(r.db.QueryRowContext(r.ctx, query, id, message, format, models.StatusSent))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status, err := r.GetNotificationStatus(id)
+			if err != nil {
+				return nil, err
+			}
+			return nil, models.Invalid(fmt.Errorf("notification %s cannot be edited in status %s", id, status))
+		}
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to update sent message",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return nil, fmt.Errorf("failed to update sent message: %w", err)
+	}
+	return nf, nil
+}
+
+func (r *NotificationRepository) RetractNotification(id string) error {
+	query := `
+  		UPDATE notifications
+  		SET status = $2
+  		WHERE id = $1 AND status = $3
   	`
 
-	result, err := r.db.ExecContext(r.ctx, query, id)
+	updated, err := r.updateWithEvent(id, models.EventRetracted, "", query,
+		id, retractTransition.to, retractTransition.from)
 	if err != nil {
-		logger.GetLoggerFromCtx(r.ctx).Error("Failed to delete notification",
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to retract notification",
 			zap.Error(err),
 			zap.String("notification_id", id))
-		return fmt.Errorf("failed to delete notification: %w", err)
+		return fmt.Errorf("failed to retract notification: %w", err)
+	}
+
+	if !updated {
+		status, err := r.GetNotificationStatus(id)
+		if err != nil {
+			return err
+		}
+		return models.Invalid(fmt.Errorf("notification %s cannot be retracted in status %s", id, status))
+	}
+
+	logger.GetLoggerFromCtx(r.ctx).Info("Notification retracted in DB",
+		zap.String("notification_id", id))
+	return nil
+}
+
+func (r *NotificationRepository) GetNotification(id string) (*models.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE id = $1
+	`
+
+	nf, err := scanNotification(r.db.QueryRowContext(r.ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("notification not found: %s", id)
+		}
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to get notification",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+	return nf, nil
+}
+
+// GetNotificationEvents returns the recorded transitions of notification id,
+// oldest first.
+func (r *NotificationRepository) GetNotificationEvents(id string) ([]*models.NotificationEvent, error) {
+	query := `
+		SELECT id, notification_id, event, status, attempt, send_at, worker_id, error, created_at
+		FROM notification_events
+		WHERE notification_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(r.ctx, query, id)
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to get notification events",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return nil, fmt.Errorf("failed to get notification events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.NotificationEvent{}
+	for rows.Next() {
+		ev := &models.NotificationEvent{}
+		if err := rows.Scan(&ev.Id, &ev.NotificationId, &ev.Event, &ev.Status, &ev.Attempt, &ev.SendAt, &ev.WorkerId, &ev.Error, &ev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification event: %w", err)
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get notification events: %w", err)
+	}
+	return events, nil
+}
+
+func (r *NotificationRepository) AcknowledgeNotification(id string, acknowledgedAt string) error {
+	query := `
+  		UPDATE notifications
+  		SET acknowledged_at = $2
+  		WHERE id = $1 AND status <> $3
+  	`
+
+	result, err := r.db.ExecContext(r.ctx, query, id, acknowledgedAt, models.StatusCancelled)
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to acknowledge notification",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return fmt.Errorf("failed to acknowledge notification: %w", err)
 	}
 
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
-		return fmt.Errorf("notification not found: %s", id)
+		status, err := r.GetNotificationStatus(id)
+		if err != nil {
+			return err
+		}
+		return models.Invalid(fmt.Errorf("notification %s cannot be acknowledged in status %s", id, status))
+	}
+	return nil
+}
+
+// SnoozeNotification schedules a delivered notification to be sent again at
+// sendTime.
+func (r *NotificationRepository) SnoozeNotification(id string, sendTime string, outbox *models.OutboxMessage) (*models.Notification, error) {
+	query := `
+  		UPDATE notifications
+  		SET time = $2,
+  		    send_at = $3,
+  		    status = $4,
+  		    delivered_via = '',
+  		    delivered_chat_id = 0,
+  		    acknowledged_at = '',
+  		    attempts = 0,
+  		    message_ids = '[]',
+  		    version = version + 1
+  		WHERE id = $1 AND status = $5 AND schedule = ''
+  		RETURNING ` + notificationColumns
+
+	var nf *models.Notification
+	err := r.db.WithTx(r.ctx, func(tx *sql.Tx) error {
+		var err error
+		nf, err = scanNotification(tx.QueryRowContext(r.ctx, query,
+			id, sendTime, outbox.SendAt, snoozeTransition.to, snoozeTransition.from))
+		if err != nil {
+			return err
+		}
+		if err := r.insertEvent(tx, id, models.EventSnoozed, ""); err != nil {
+			return err
+		}
+		return r.insertOutbox(tx, nf, outbox)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status, err := r.GetNotificationStatus(id)
+			if err != nil {
+				return nil, err
+			}
+			return nil, models.Invalid(fmt.Errorf("notification %s cannot be snoozed in status %s", id, status))
+		}
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to snooze notification",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return nil, fmt.Errorf("failed to snooze notification: %w", err)
 	}
 
-	logger.GetLoggerFromCtx(r.ctx).Info("Notification deleted from DB",
+	logger.GetLoggerFromCtx(r.ctx).Info("Notification snoozed in DB",
+		zap.String("notification_id", id),
+		zap.String("time", sendTime),
+		zap.Int("version", nf.Version))
+	return nf, nil
+}
+
+func (r *NotificationRepository) CancelNotification(id string) error {
+	query := `
+  		UPDATE notifications
+  		SET status = $2
+  		WHERE id = $1 AND status = $3
+  	`
+
+	updated, err := r.updateWithEvent(id, models.EventCancelled, "", query,
+		id, cancelTransition.to, cancelTransition.from)
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to cancel notification",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return fmt.Errorf("failed to cancel notification: %w", err)
+	}
+
+	if !updated {
+		status, err := r.GetNotificationStatus(id)
+		if err != nil {
+			return err
+		}
+		return models.Invalid(fmt.Errorf("notification %s cannot be cancelled in status %s", id, status))
+	}
+
+	logger.GetLoggerFromCtx(r.ctx).Info("Notification cancelled in DB",
 		zap.String("notification_id", id))
 	return nil
 }
 
-func (r *NotificationRepository) GetAllNotifications() ([]*models.Notification, error) {
+func (r *NotificationRepository) RescheduleNotification(id string, sendTime string, message string, outbox *models.OutboxMessage) (*models.Notification, error) {
+	query := `
+  		UPDATE notifications
+  		SET time = $2,
+  		    message = COALESCE(NULLIF($3, ''), message),
+  		    send_at = $4,
+  		    status = $5,
+  		    attempts = 0,
+  		    message_ids = '[]',
+  		    version = version + 1
+  		WHERE id = $1 AND status = $6
+  		RETURNING ` + notificationColumns
+
+	var nf *models.Notification
+	err := r.db.WithTx(r.ctx, func(tx *sql.Tx) error {
+		var err error
+		nf, err = scanNotification(tx.QueryRowContext(r.ctx, query,
+			id, sendTime, message, outbox.SendAt, rescheduleTransition.to, rescheduleTransition.from))
+		if err != nil {
+			return err
+		}
+		if err := r.insertEvent(tx, id, models.EventRescheduled, ""); err != nil {
+			return err
+		}
+		return r.insertOutbox(tx, nf, outbox)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status, err := r.GetNotificationStatus(id)
+			if err != nil {
+				return nil, err
+			}
+			return nil, models.Invalid(fmt.Errorf("notification %s cannot be rescheduled in status %s", id, status))
+		}
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to reschedule notification",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return nil, fmt.Errorf("failed to reschedule notification: %w", err)
+	}
+
+	logger.GetLoggerFromCtx(r.ctx).Info("Notification rescheduled in DB",
+		zap.String("notification_id", id),
+		zap.Int("version", nf.Version))
+	return nf, nil
+}
+
+func (r *NotificationRepository) ScheduleRetry(id string, version int, lastError string, outbox *models.OutboxMessage) (*models.Notification, error) {
+	query := `
+  		UPDATE notifications
+  		SET status = $5,
+  		    version = version + 1,
+  		    send_at = $3,
+  		    last_error = $4
+  		WHERE id = $1 AND version = $2 AND status = $6
+  		RETURNING ` + notificationColumns
+
+	var nf *models.Notification
+	err := r.db.WithTx(r.ctx, func(tx *sql.Tx) error {
+		var err error
+		nf, err = scanNotification(tx.QueryRowContext(r.ctx, query,
+			id, version, outbox.SendAt, lastError, retryTransition.to, retryTransition.from))
+		if err != nil {
+			return err
+		}
+		if err := r.insertEvent(tx, id, models.EventRetryScheduled, lastError); err != nil {
+			return err
+		}
+		return r.insertOutbox(tx, nf, outbox)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("notification %s is no longer being sent", id)
+		}
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to schedule retry",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return nil, fmt.Errorf("failed to schedule retry: %w", err)
+	}
+
+	logger.GetLoggerFromCtx(r.ctx).Info("Retry scheduled in DB",
+		zap.String("notification_id", id),
+		zap.Int("attempts", nf.Attempts),
+		zap.Int("version", nf.Version))
+	return nf, nil
+}
+
+// MarkNotificationSending claims notification id at version for delivery and
+// leases it to the caller for lease. It reports false when the notification
+// is already claimed, sent, cancelled or rescheduled; the caller must then
+// not send it. A lease that runs out is recovered by GetExpiredLeases.
+func (r *NotificationRepository) MarkNotificationSending(id string, version int, lease time.Duration) (bool, error) {
 	query := `
-		SELECT id, message, time, status, chat_id
+  		UPDATE notifications
+  		SET status = $3,
+  		    attempts = attempts + 1,
+  		    lease_until = now() + make_interval(secs => $5)
+  		WHERE id = $1 AND version = $2 AND status = $4
+  	`
+
+	claimed, err := r.updateWithEvent(id, models.EventSending, "", query,
+		id, version, claimTransition.to, claimTransition.from, lease.Seconds())
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to mark notification as sending",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return false, fmt.Errorf("failed to mark notification as sending: %w", err)
+	}
+	return claimed, nil
+}
+
+// ExtendLease renews the lease on notification id at version for another
+// lease. It reports false when the notification is no longer being sent
+// under that version, i.e. the lease was already recovered.
+func (r *NotificationRepository) ExtendLease(id string, version int, lease time.Duration) (bool, error) {
+	query := `
+  		UPDATE notifications
+  		SET lease_until = now() + make_interval(secs => $3)
+  		WHERE id = $1 AND version = $2 AND status = $4
+  	`
+
+	result, err := r.db.ExecContext(r.ctx, query, id, version, lease.Seconds(), models.StatusSending)
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to extend delivery lease",
+			zap.Error(err),
+			zap.String("notification_id", id))
+		return false, fmt.Errorf("failed to extend delivery lease: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// GetExpiredLeases returns up to limit notifications still being sent after
+// their lease ran out, most overdue first.
+func (r *NotificationRepository) GetExpiredLeases(limit int) ([]*models.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
 		FROM notifications
+		WHERE status = $1 AND lease_until < now()
+		ORDER BY lease_until
+		LIMIT $2
 	`
 
-	rows, err := r.db.QueryContext(r.ctx, query)
+	rows, err := r.db.QueryContext(r.ctx, query, models.StatusSending, limit)
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to get expired leases",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to get expired leases: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		nf, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, nf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get expired leases: %w", err)
+	}
+	return notifications, nil
+}
+
+// ListNotifications returns one page of notifications matching q together
+// with the number of all matching notifications. A page of q.Limit+1 rows
+// tells the caller there is a next one.
+func (r *NotificationRepository) ListNotifications(q *models.NotificationQuery) ([]*models.Notification, int, error) {
+	var conditions []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(q.Statuses) > 0 {
+		placeholders := make([]string, len(q.Statuses))
+		for i, status := range q.Statuses {
+			placeholders[i] = arg(status)
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if q.ChatId != 0 {
+		conditions = append(conditions, "chat_id = "+arg(q.ChatId))
+	}
+	if q.Channel != "" {
+		conditions = append(conditions, "channel = "+arg(q.Channel))
+	}
+	if !q.From.IsZero() {
+		conditions = append(conditions, "send_at >= "+arg(q.From))
+	}
+	if !q.To.IsZero() {
+		conditions = append(conditions, "send_at < "+arg(q.To))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	countQuery := `
+		SELECT count(*)
+		FROM notifications
+		` + where
+	if err := r.db.QueryRowContext(r.ctx, countQuery, args...).Scan(&total); err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to count notifications",
+			zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	order, after := "ASC", ">"
+	if q.Desc {
+		order, after = "DESC", "<"
+	}
+	if q.AfterId != "" {
+		conditions = append(conditions, "(send_at, id) "+after+" ("+arg(q.AfterSendAt)+", "+arg(q.AfterId)+")")
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		` + where + `
+		ORDER BY send_at ` + order + `, id ` + order + `
+		LIMIT ` + arg(q.Limit+1)
+
+	rows, err := r.db.QueryContext(r.ctx, query, args...)
 	if err != nil {
-		logger.GetLoggerFromCtx(r.ctx).Error("Failed to get all notifications",
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to list notifications",
 			zap.Error(err))
-		return nil, fmt.Errorf("failed to get all notifications: %w", err)
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		nf, err := scanNotification(rows)
+		if err != nil {
+			logger.GetLoggerFromCtx(r.ctx).Error("Failed to scan notification",
+				zap.Error(err))
+			continue
+		}
+		notifications = append(notifications, nf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	return notifications, total, nil
+}
+
+// GetPendingNotifications returns the Telegram notifications of a chat that
+// are still waiting to be sent.
+func (r *NotificationRepository) GetPendingNotifications(chatId int64) ([]*models.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE chat_id = $1 AND channel = $2 AND status IN ($3, $4)
+	`
+
+	rows, err := r.db.QueryContext(r.ctx, query, chatId, models.ChannelTelegram, models.StatusCreated, models.StatusSending)
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to get pending notifications",
+			zap.Error(err),
+			zap.Int64("chat_id", chatId))
+		return nil, fmt.Errorf("failed to get pending notifications: %w", err)
 	}
 	defer rows.Close()
 
 	var notifications []*models.Notification
 	for rows.Next() {
-		nf := &models.Notification{}
-		err := rows.Scan(&nf.Id, &nf.Message, &nf.Time, &nf.Status, &nf.ChatId)
+		nf, err := scanNotification(rows)
 		if err != nil {
 			logger.GetLoggerFromCtx(r.ctx).Error("Failed to scan notification",
 				zap.Error(err))
@@ -150,3 +1060,43 @@ func (r *NotificationRepository) GetAllNotifications() ([]*models.Notification,
 
 	return notifications, nil
 }
+
+// GetChatTimezone returns the IANA timezone set for a chat, or an empty
+// string if none was set.
+func (r *NotificationRepository) GetChatTimezone(chatId int64) (string, error) {
+	query := `
+  		SELECT timezone
+  		FROM chat_settings
+  		WHERE chat_id = $1
+  	`
+
+	var timezone string
+	err := r.db.QueryRowContext(r.ctx, query, chatId).Scan(&timezone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to get chat timezone",
+			zap.Error(err),
+			zap.Int64("chat_id", chatId))
+		return "", fmt.Errorf("failed to get chat timezone: %w", err)
+	}
+	return timezone, nil
+}
+
+func (r *NotificationRepository) SetChatTimezone(chatId int64, timezone string) error {
+	query := `
+  		INSERT INTO chat_settings (chat_id, timezone)
+  		VALUES ($1, $2)
+  		ON CONFLICT (chat_id) DO UPDATE SET timezone = EXCLUDED.timezone
+  	`
+
+	_, err := r.db.ExecContext(r.ctx, query, chatId, timezone)
+	if err != nil {
+		logger.GetLoggerFromCtx(r.ctx).Error("Failed to set chat timezone",
+			zap.Error(err),
+			zap.Int64("chat_id", chatId))
+		return fmt.Errorf("failed to set chat timezone: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"DelayedNotifier/internal/models"
+	"fmt"
+)
+
+// transition is a status change made by a conditional UPDATE that sets to
+// and only matches a notification still in from, so a stale or repeated
+// change updates nothing instead of moving the notification back.
+type transition struct {
+	from models.Status
+	to   models.Status
+}
+
+func newTransition(from, to models.Status) (transition, error) {
+	if !models.CanTransition(from, to) {
+		return transition{}, fmt.Errorf("notification cannot move from %s to %s", from, to)
+	}
+	return transition{from: from, to: to}, nil
+}
+
+func mustTransition(from, to models.Status) transition {
+	t, err := newTransition(from, to)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+var (
+	claimTransition      = mustTransition(models.StatusCreated, models.StatusSending)
+	sentTransition       = mustTransition(models.StatusSending, models.StatusSent)
+	failedTransition     = mustTransition(models.StatusSending, models.StatusFailed)
+	retryTransition      = mustTransition(models.StatusSending, models.StatusCreated)
+	cancelTransition     = mustTransition(models.StatusCreated, models.StatusCancelled)
+	rescheduleTransition = mustTransition(models.StatusCreated, models.StatusCreated)
+	snoozeTransition     = mustTransition(models.StatusSent, models.StatusCreated)
+	retractTransition    = mustTransition(models.StatusSent, models.StatusRetracted)
+)
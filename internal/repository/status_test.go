@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"DelayedNotifier/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransition(t *testing.T) {
+	next, err := newTransition(models.StatusFailed, models.StatusCreated)
+	require.NoError(t, err)
+	require.Equal(t, transition{from: models.StatusFailed, to: models.StatusCreated}, next)
+
+	_, err = newTransition(models.StatusSent, models.StatusSending)
+	require.EqualError(t, err, "notification cannot move from sent to sending")
+
+	require.Panics(t, func() { mustTransition(models.StatusCancelled, models.StatusCreated) })
+}
@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+	"github.com/wb-go/wbf/dbpg"
+	"go.uber.org/zap"
+)
+
+type PostgresScheduler struct {
+	pollConfig
+	db      *dbpg.DB
+	handler Handler
+}
+
+func NewPostgresScheduler(db *dbpg.DB, cfg *config.Config, handler Handler) *PostgresScheduler {
+	return &PostgresScheduler{
+		pollConfig: newPollConfig(cfg),
+		db:         db,
+		handler:    handler,
+	}
+}
+
+func (s *PostgresScheduler) Publish(data []byte, ctx context.Context, routingKey string, delay time.Duration) error {
+	query := `
+		INSERT INTO scheduled_messages (routing_key, payload, due_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 millisecond')
+	`
+
+	_, err := s.db.ExecContext(ctx, query, routingKey, data, delay.Milliseconds())
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error("Failed to schedule message in Postgres",
+			zap.Error(err),
+			zap.String("routing_key", routingKey))
+		return fmt.Errorf("failed to schedule message: %w", err)
+	}
+
+	logger.GetLoggerFromCtx(ctx).Info("Message scheduled in Postgres",
+		zap.String("routing_key", routingKey),
+		zap.Duration("delay", delay))
+	return nil
+}
+
+func (s *PostgresScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for i := 0; i < s.batchSize; i++ {
+				processed, err := s.processNext(ctx)
+				if err != nil {
+					logger.GetLoggerFromCtx(ctx).Error("Failed to process scheduled message",
+						zap.Error(err))
+					break
+				}
+				if !processed {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (s *PostgresScheduler) Close() {}
+
+// processNext claims a single due row by pushing its due_at past the
+// visibility timeout, then runs the handler outside any transaction. A row
+// whose worker dies becomes due again once the timeout passes; the handler
+// skips notifications another worker already took. Retries of failed
+// deliveries are scheduled by the handler itself, so a handler error means it
+// never got that far, e.g. the database was briefly unavailable; the row is
+// retried with a backoff and marked dead once it runs out of attempts.
+// Running the handler again is safe: it only delivers after claiming the
+// notification.
+func (s *PostgresScheduler) processNext(ctx context.Context) (bool, error) {
+	claimQuery := `
+		UPDATE scheduled_messages
+		SET due_at = NOW() + $1 * INTERVAL '1 millisecond',
+		    attempts = attempts + 1
+		WHERE id = (
+			SELECT id
+			FROM scheduled_messages
+			WHERE due_at <= NOW() AND dead_at IS NULL
+			ORDER BY due_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, payload, attempts
+	`
+
+	var (
+		id       int64
+		payload  []byte
+		attempts int
+	)
+	err := s.db.QueryRowContext(ctx, claimQuery, s.visibilityTimeout.Milliseconds()).Scan(&id, &payload, &attempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var notification models.Notification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		logger.GetLoggerFromCtx(ctx).Error("Failed to unmarshal scheduled notification",
+			zap.Error(err),
+			zap.Int64("scheduled_id", id))
+		return true, s.markDead(ctx, id, err)
+	}
+
+	if err := s.handler(&notification); err != nil {
+		if attempts >= s.maxAttempts {
+			logger.GetLoggerFromCtx(ctx).Error("Scheduled notification exhausted attempts",
+				zap.Error(err),
+				zap.String("notification_id", notification.Id),
+				zap.Int("attempts", attempts))
+			return true, s.markDead(ctx, id, err)
+		}
+		logger.GetLoggerFromCtx(ctx).Error("Failed to process scheduled notification",
+			zap.Error(err),
+			zap.String("notification_id", notification.Id),
+			zap.Int("attempts", attempts))
+		return true, s.retryLater(ctx, id, attempts, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `DELETE FROM scheduled_messages WHERE id = $1`, id)
+	if err != nil {
+		return true, err
+	}
+
+	logger.GetLoggerFromCtx(ctx).Info("Notification processed successfully",
+		zap.String("notification_id", notification.Id))
+	return true, nil
+}
+
+func (s *PostgresScheduler) retryLater(ctx context.Context, id int64, attempts int, cause error) error {
+	query := `
+		UPDATE scheduled_messages
+		SET last_error = $2,
+		    due_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, cause.Error(), retryDelay(attempts).Milliseconds())
+	return err
+}
+
+func (s *PostgresScheduler) markDead(ctx context.Context, id int64, cause error) error {
+	query := `
+		UPDATE scheduled_messages
+		SET dead_at = NOW(),
+		    last_error = $2
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, cause.Error())
+	return err
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"DelayedNotifier/internal/rabbitmq"
+	"context"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+)
+
+type RabbitMQScheduler struct {
+	client   *rabbitmq.ClientRabbitMQ
+	producer *rabbitmq.Producer
+	consumer *rabbitmq.Consumer
+}
+
+func NewRabbitMQScheduler(cfg *config.Config, ctx context.Context, handler Handler) (*RabbitMQScheduler, error) {
+	client := rabbitmq.NewClientRabbitMQ(cfg, ctx)
+	if err := client.Init(); err != nil {
+		return nil, err
+	}
+
+	if err := client.SetupInfrastructure(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &RabbitMQScheduler{
+		client:   client,
+		producer: rabbitmq.NewProducer(client, cfg),
+		consumer: rabbitmq.NewConsumer(client, cfg, handler),
+	}, nil
+}
+
+func (s *RabbitMQScheduler) Publish(data []byte, ctx context.Context, routingKey string, delay time.Duration) error {
+	return s.producer.Publish(data, ctx, routingKey, delay)
+}
+
+func (s *RabbitMQScheduler) Start(ctx context.Context) {
+	s.consumer.Start(ctx)
+}
+
+func (s *RabbitMQScheduler) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,221 @@
+package scheduler
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/wb-go/wbf/config"
+	wbfredis "github.com/wb-go/wbf/redis"
+	"go.uber.org/zap"
+)
+
+const (
+	redisDueKey        = "scheduler:due"
+	redisProcessingKey = "scheduler:processing"
+	redisPayloadsKey   = "scheduler:payloads"
+	redisDeadKey       = "scheduler:dead"
+	redisAttemptsKey   = "scheduler:attempts"
+)
+
+// claimScript first returns messages whose visibility timeout expired back to
+// the due set, then atomically moves the earliest due message into the
+// processing set with visibility deadline ARGV[2] and returns its id,
+// payload and number of claims so far. The deadline identifies this claim in
+// releaseScript.
+var claimScript = goredis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('ZADD', KEYS[1], ARGV[1], id)
+end
+
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 1)
+if #ids == 0 then
+	return {}
+end
+redis.call('ZREM', KEYS[1], ids[1])
+redis.call('ZADD', KEYS[2], ARGV[2], ids[1])
+local attempts = redis.call('HINCRBY', KEYS[4], ids[1], 1)
+return {ids[1], redis.call('HGET', KEYS[3], ids[1]) or '', tostring(attempts)}
+`)
+
+// releaseScript finishes the claim of message ARGV[1] made with deadline
+// ARGV[2]. ARGV[3] is "done" to remove the message, "dead" to move its
+// payload to the dead set, or "retry" to put it back into the due set with
+// score ARGV[4]. It does nothing when the message has since been handed to another worker, so a
+// slow worker cannot remove a message out from under the one now holding it.
+var releaseScript = goredis.NewScript(`
+local deadline = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not deadline or tonumber(deadline) ~= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('ZREM', KEYS[1], ARGV[1])
+if ARGV[3] == 'retry' then
+	redis.call('ZADD', KEYS[4], ARGV[4], ARGV[1])
+	return 1
+end
+if ARGV[3] == 'dead' then
+	redis.call('HSET', KEYS[3], ARGV[1], redis.call('HGET', KEYS[2], ARGV[1]) or '')
+end
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[5], ARGV[1])
+return 1
+`)
+
+type RedisScheduler struct {
+	pollConfig
+	client  *wbfredis.Client
+	handler Handler
+}
+
+func NewRedisScheduler(client *wbfredis.Client, cfg *config.Config, handler Handler) *RedisScheduler {
+	return &RedisScheduler{
+		pollConfig: newPollConfig(cfg),
+		client:     client,
+		handler:    handler,
+	}
+}
+
+func (s *RedisScheduler) Publish(data []byte, ctx context.Context, routingKey string, delay time.Duration) error {
+	id := uuid.New().String()
+	dueAt := time.Now().Add(delay)
+
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, redisPayloadsKey, id, data)
+		pipe.ZAdd(ctx, redisDueKey, &goredis.Z{Score: float64(dueAt.UnixMilli()), Member: id})
+		return nil
+	})
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error("Failed to schedule message in Redis",
+			zap.Error(err),
+			zap.String("routing_key", routingKey))
+		return fmt.Errorf("failed to schedule message: %w", err)
+	}
+
+	logger.GetLoggerFromCtx(ctx).Info("Message scheduled in Redis",
+		zap.String("routing_key", routingKey),
+		zap.String("scheduled_id", id),
+		zap.Duration("delay", delay))
+	return nil
+}
+
+func (s *RedisScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.processDue(ctx); err != nil {
+				logger.GetLoggerFromCtx(ctx).Error("Failed to process scheduled messages",
+					zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *RedisScheduler) Close() {}
+
+// processDue claims and handles due messages one at a time, so every message
+// gets the full visibility timeout no matter how long the ones before it took.
+func (s *RedisScheduler) processDue(ctx context.Context) error {
+	for i := 0; i < s.batchSize; i++ {
+		processed, err := s.processNext(ctx)
+		if err != nil || !processed {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RedisScheduler) processNext(ctx context.Context) (bool, error) {
+	now := time.Now()
+	deadline := now.Add(s.visibilityTimeout).UnixMilli()
+
+	res, err := claimScript.Run(ctx, s.client,
+		[]string{redisDueKey, redisProcessingKey, redisPayloadsKey, redisAttemptsKey},
+		now.UnixMilli(), deadline,
+	).StringSlice()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim due message: %w", err)
+	}
+	if len(res) < 3 {
+		return false, nil
+	}
+
+	attempts, err := strconv.Atoi(res[2])
+	if err != nil {
+		return false, fmt.Errorf("failed to parse attempts of message %s: %w", res[0], err)
+	}
+	s.handle(ctx, res[0], deadline, []byte(res[1]), attempts)
+	return true, nil
+}
+
+// handle runs the handler for one claimed message. Retries of failed
+// deliveries are scheduled by the handler itself, so a handler error means it
+// never got that far; the message is retried with a backoff and moved to the
+// dead set once it runs out of attempts, like in the Postgres scheduler.
+func (s *RedisScheduler) handle(ctx context.Context, id string, deadline int64, payload []byte, attempts int) {
+	var notification models.Notification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		logger.GetLoggerFromCtx(ctx).Error("Failed to unmarshal scheduled notification",
+			zap.Error(err),
+			zap.String("scheduled_id", id))
+		s.release(ctx, id, deadline, releaseDead, 0)
+		return
+	}
+
+	if err := s.handler(&notification); err != nil {
+		if attempts >= s.maxAttempts {
+			logger.GetLoggerFromCtx(ctx).Error("Scheduled notification exhausted attempts",
+				zap.Error(err),
+				zap.String("notification_id", notification.Id),
+				zap.Int("attempts", attempts))
+			s.release(ctx, id, deadline, releaseDead, 0)
+			return
+		}
+		logger.GetLoggerFromCtx(ctx).Error("Failed to process scheduled notification",
+			zap.Error(err),
+			zap.String("notification_id", notification.Id),
+			zap.Int("attempts", attempts))
+		s.release(ctx, id, deadline, releaseRetry, time.Now().Add(retryDelay(attempts)).UnixMilli())
+		return
+	}
+
+	s.release(ctx, id, deadline, releaseDone, 0)
+
+	logger.GetLoggerFromCtx(ctx).Info("Notification processed successfully",
+		zap.String("notification_id", notification.Id))
+}
+
+const (
+	releaseDone  = "done"
+	releaseDead  = "dead"
+	releaseRetry = "retry"
+)
+
+func (s *RedisScheduler) release(ctx context.Context, id string, deadline int64, mode string, retryAt int64) {
+	released, err := releaseScript.Run(ctx, s.client,
+		[]string{redisProcessingKey, redisPayloadsKey, redisDeadKey, redisDueKey, redisAttemptsKey},
+		id, deadline, mode, retryAt,
+	).Int()
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error("Failed to release scheduled message",
+			zap.Error(err),
+			zap.String("scheduled_id", id))
+		return
+	}
+	if released == 0 {
+		logger.GetLoggerFromCtx(ctx).Warn("Scheduled message was handed to another worker after its visibility timeout",
+			zap.String("scheduled_id", id))
+	}
+}
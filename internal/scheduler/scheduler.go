@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"DelayedNotifier/internal/models"
+	"context"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+)
+
+const (
+	BackendRabbitMQ = "rabbitmq"
+	BackendPostgres = "postgres"
+	BackendRedis    = "redis"
+)
+
+const (
+	defaultPollInterval      = time.Second
+	defaultBatchSize         = 100
+	defaultMaxAttempts       = 4
+	retryBaseDelay           = 2 * time.Second
+	defaultVisibilityTimeout = 30 * time.Second
+)
+
+type Handler func(*models.Notification) error
+
+type Scheduler interface {
+	Publish(data []byte, ctx context.Context, routingKey string, delay time.Duration) error
+	Start(ctx context.Context)
+	Close()
+}
+
+type pollConfig struct {
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	// visibilityTimeout is how long a claimed message stays hidden from
+	// other workers before it is handed out again.
+	visibilityTimeout time.Duration
+}
+
+func newPollConfig(cfg *config.Config) pollConfig {
+	pc := pollConfig{
+		pollInterval:      time.Duration(cfg.GetInt("SCHEDULER_POLL_INTERVAL_MS")) * time.Millisecond,
+		batchSize:         cfg.GetInt("SCHEDULER_BATCH_SIZE"),
+		maxAttempts:       cfg.GetInt("SCHEDULER_MAX_ATTEMPTS"),
+		visibilityTimeout: time.Duration(cfg.GetInt("SCHEDULER_VISIBILITY_TIMEOUT_MS")) * time.Millisecond,
+	}
+	if pc.pollInterval <= 0 {
+		pc.pollInterval = defaultPollInterval
+	}
+	if pc.batchSize <= 0 {
+		pc.batchSize = defaultBatchSize
+	}
+	if pc.maxAttempts <= 0 {
+		pc.maxAttempts = defaultMaxAttempts
+	}
+	if pc.visibilityTimeout <= 0 {
+		pc.visibilityTimeout = defaultVisibilityTimeout
+	}
+	return pc
+}
+
+func retryDelay(attempts int) time.Duration {
+	return retryBaseDelay << (attempts - 1)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/storage"
+	"DelayedNotifier/pkg/logger"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+var photoExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".webp": true}
+
+func validateAttachment(attachment *models.Attachment) error {
+	switch attachment.Type {
+	case models.AttachmentPhoto, models.AttachmentDocument:
+		if (attachment.URL == "") == (attachment.FileKey == "") {
+			return errors.New("either url or an uploaded file is required")
+		}
+		if attachment.URL != "" {
+			u, err := url.ParseRequestURI(attachment.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid url: %s", attachment.URL)
+			}
+		}
+	case models.AttachmentLocation:
+		if attachment.URL != "" || attachment.FileKey != "" {
+			return errors.New("location cannot have a file")
+		}
+		if attachment.Latitude < -90 || attachment.Latitude > 90 || attachment.Longitude < -180 || attachment.Longitude > 180 {
+			return fmt.Errorf("invalid coordinates %v, %v", attachment.Latitude, attachment.Longitude)
+		}
+	default:
+		return fmt.Errorf("unsupported type %q (use photo, document or location)", attachment.Type)
+	}
+	return nil
+}
+
+// CreateNotificationWithAttachment stores an uploaded file and creates a
+// notification delivering it. Images are sent as photos and anything else
+// as documents unless the notification sets attachment.type itself. The file
+// is removed again if the notification is rejected.
+func (service *DelayedNotifierService) CreateNotificationWithAttachment(nf *models.Notification, fileName string, file io.Reader) (string, error) {
+	if service.storage == nil {
+		return "", errors.New("attachment uploads are not configured")
+	}
+	if nf.Attachment == nil {
+		nf.Attachment = &models.Attachment{Type: attachmentType(fileName)}
+	}
+	if nf.Attachment.URL != "" || nf.Attachment.FileKey != "" {
+		return "", models.Invalid(errors.New("attachment url cannot be combined with an uploaded file"))
+	}
+
+	key, err := service.storage.Save(fileName, file)
+	if err != nil {
+		if errors.Is(err, storage.ErrTooLarge) {
+			return "", models.Invalid(err)
+		}
+		return "", err
+	}
+	nf.Attachment.FileKey = key
+	nf.Attachment.FileName = filepath.Base(fileName)
+
+	id, err := service.createNotification(nf)
+	if err != nil {
+		if delErr := service.storage.Delete(key); delErr != nil {
+			logger.GetLoggerFromCtx(service.ctx).Warn("Failed to delete attachment of rejected notification",
+				zap.Error(delErr),
+				zap.String("file_key", key))
+		}
+		return "", err
+	}
+	return id, nil
+}
+
+// releaseAttachment deletes the uploaded file of nf once nothing will send nf
+// again. A failure only leaves the file behind, so it is logged.
+func (service *DelayedNotifierService) releaseAttachment(nf *models.Notification) {
+	if service.storage == nil || nf.Attachment == nil || nf.Attachment.FileKey == "" {
+		return
+	}
+	if err := service.storage.Delete(nf.Attachment.FileKey); err != nil {
+		logger.GetLoggerFromCtx(service.ctx).Warn("Failed to delete attachment of finished notification",
+			zap.Error(err),
+			zap.String("notification_id", nf.Id),
+			zap.String("file_key", nf.Attachment.FileKey))
+	}
+}
+
+// lastDelivery reports whether nf will not be sent again once the current
+// delivery ends with occurrence: a recurring series is over, and a one-off
+// notification can only come back through its snooze button after being sent.
+func lastDelivery(nf *models.Notification, occurrence *models.Occurrence, sent bool) bool {
+	if occurrence != nil {
+		return occurrence.Outbox == nil
+	}
+	return !sent || !hasAction(nf, models.ActionSnooze)
+}
+
+func attachmentType(fileName string) string {
+	if photoExtensions[strings.ToLower(filepath.Ext(fileName))] {
+		return models.AttachmentPhoto
+	}
+	return models.AttachmentDocument
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/repository/mocks"
+	servicemocks "DelayedNotifier/internal/service/mocks"
+	"DelayedNotifier/internal/telegram"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/config"
+	"go.uber.org/mock/gomock"
+)
+
+func TestValidateAttachment(t *testing.T) {
+	cases := []struct {
+		name       string
+		attachment models.Attachment
+		expErr     string
+	}{
+		{name: "photo url", attachment: models.Attachment{Type: models.AttachmentPhoto, URL: "https://example.com/cat.jpg"}},
+		{name: "uploaded document", attachment: models.Attachment{Type: models.AttachmentDocument, FileKey: "key.pdf"}},
+		{name: "location", attachment: models.Attachment{Type: models.AttachmentLocation, Latitude: 55.75, Longitude: 37.62}},
+		{name: "no file", attachment: models.Attachment{Type: models.AttachmentPhoto}, expErr: "either url or an uploaded file is required"},
+		{name: "url and file", attachment: models.Attachment{Type: models.AttachmentDocument, URL: "https://example.com/a.pdf", FileKey: "key.pdf"}, expErr: "either url or an uploaded file is required"},
+		{name: "bad scheme", attachment: models.Attachment{Type: models.AttachmentPhoto, URL: "file:///etc/passwd"}, expErr: "invalid url"},
+		{name: "location with file", attachment: models.Attachment{Type: models.AttachmentLocation, URL: "https://example.com/map.png"}, expErr: "location cannot have a file"},
+		{name: "bad coordinates", attachment: models.Attachment{Type: models.AttachmentLocation, Latitude: 91}, expErr: "invalid coordinates"},
+		{name: "unknown type", attachment: models.Attachment{Type: "video", URL: "https://example.com/a.mp4"}, expErr: `unsupported type "video"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateAttachment(&tc.attachment)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateForSenderAttachment(t *testing.T) {
+	nf := &models.Notification{
+		Channel:    models.ChannelEmail,
+		Recipient:  "user@example.com",
+		Message:    "Report",
+		Attachment: &models.Attachment{Type: models.AttachmentDocument, URL: "https://example.com/report.pdf"},
+	}
+	require.ErrorContains(t, validateForSender(NewEmailSender(nil), nf), "does not support attachments")
+
+	nf = &models.Notification{ChatId: 1, Attachment: &models.Attachment{Type: models.AttachmentPhoto}}
+	require.ErrorContains(t, validateForSender(NewTelegramSender(nil, nil, nil), nf), "invalid attachment: either url")
+}
+
+func TestTelegramSenderAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := servicemocks.NewMockTelegramClientInterface(ctrl)
+	storage := servicemocks.NewMockAttachmentStorageInterface(ctrl)
+	sender := NewTelegramSender(client, nil, storage)
+
+	storage.EXPECT().Open("key.pdf").Return(io.NopCloser(strings.NewReader("%PDF-1.7")), nil).Times(1)
+	client.EXPECT().SendAttachment(int64(42), gomock.Any(), "Weekly report", "", gomock.Nil()).
+		DoAndReturn(func(chatId int64, attachment telegram.Attachment, caption, parseMode string, keyboard [][]telegram.Button) (int, error) {
+			require.Equal(t, models.AttachmentDocument, attachment.Type)
+			require.Equal(t, "report.pdf", attachment.FileName)
+			data, err := io.ReadAll(attachment.Reader)
+			require.NoError(t, err)
+			require.Equal(t, "%PDF-1.7", string(data))
+			return 7, nil
+		}).Times(1)
+
+	ids, err := sender.Send(&models.Notification{
+		ChatId:     42,
+		Message:    "Weekly report",
+		Attachment: &models.Attachment{Type: models.AttachmentDocument, FileKey: "key.pdf", FileName: "report.pdf"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{7}, ids)
+
+	// A message too long for a caption follows the attachment, and the
+	// keyboard goes under the message.
+	long := strings.Repeat("a", telegramMaxCaptionLength+1)
+	buttons := [][]models.Button{{{Text: "Done", Action: models.ActionAck}}}
+	gomock.InOrder(
+		client.EXPECT().SendAttachment(int64(42), gomock.Any(), "", "", gomock.Nil()).Return(8, nil),
+		client.EXPECT().SendMessage(int64(42), long, "", gomock.Not(gomock.Nil())).Return(9, nil),
+	)
+	ids, err = sender.Send(&models.Notification{
+		Id:         "id",
+		ChatId:     42,
+		Message:    long,
+		Buttons:    buttons,
+		Attachment: &models.Attachment{Type: models.AttachmentPhoto, URL: "https://example.com/cat.jpg"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{8, 9}, ids)
+
+	// Locations have no caption.
+	gomock.InOrder(
+		client.EXPECT().SendAttachment(int64(42), gomock.Any(), "", "", gomock.Nil()).Return(10, nil),
+		client.EXPECT().SendMessage(int64(42), "Meet here", "", gomock.Nil()).Return(11, nil),
+	)
+	ids, err = sender.Send(&models.Notification{
+		ChatId:     42,
+		Message:    "Meet here",
+		Attachment: &models.Attachment{Type: models.AttachmentLocation, Latitude: 55.75, Longitude: 37.62},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{10, 11}, ids)
+
+	storage.EXPECT().Open("gone.pdf").Return(nil, fs.ErrNotExist).Times(1)
+	_, err = sender.Send(&models.Notification{
+		ChatId:     42,
+		Attachment: &models.Attachment{Type: models.AttachmentDocument, FileKey: "gone.pdf"},
+	})
+	require.True(t, IsPermanent(err))
+}
+
+func TestDelayedNotifierService_CreateNotificationWithAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+	storage := servicemocks.NewMockAttachmentStorageInterface(ctrl)
+	cfg := config.New()
+	cfg.EnableEnv("")
+	cfg.SetDefault("ROUTING_KEY", "test.routing.key")
+
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: testSenders(),
+		storage: storage,
+		redis:   redisClient,
+		ctx:     setupTestContext(),
+		cfg:     cfg,
+	}
+
+	storage.EXPECT().Save("cat.PNG", gomock.Any()).Return("key.png", nil).Times(1)
+	repo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(nf *models.Notification, outbox *models.OutboxMessage) error {
+			require.Equal(t, &models.Attachment{Type: models.AttachmentPhoto, FileKey: "key.png", FileName: "cat.PNG"}, nf.Attachment)
+			return nil
+		}).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	_, err := srv.CreateNotificationWithAttachment(&models.Notification{
+		Message: "Look",
+		Time:    "2026-02-13T15:00:00+03:00",
+		ChatId:  42,
+	}, "cat.PNG", strings.NewReader("png"))
+	require.NoError(t, err)
+
+	// A rejected notification does not leave its file behind.
+	storage.EXPECT().Save("report.pdf", gomock.Any()).Return("key.pdf", nil).Times(1)
+	storage.EXPECT().Delete("key.pdf").Return(nil).Times(1)
+	_, err = srv.CreateNotificationWithAttachment(&models.Notification{
+		Message:   "Report",
+		Time:      "2026-02-13T15:00:00+03:00",
+		Channel:   models.ChannelEmail,
+		Recipient: "user@example.com",
+	}, "report.pdf", strings.NewReader("%PDF-1.7"))
+	require.ErrorContains(t, err, "does not support attachments")
+
+	storage.EXPECT().Save("big.bin", gomock.Any()).Return("", errors.New("attachment is too large")).Times(1)
+	_, err = srv.CreateNotificationWithAttachment(&models.Notification{Message: "Big", ChatId: 42}, "big.bin", strings.NewReader("x"))
+	require.ErrorContains(t, err, "too large")
+
+	_, err = srv.CreateNotification(&models.Notification{
+		Message:    "Sneaky",
+		Time:       "2026-02-13T15:00:00+03:00",
+		ChatId:     42,
+		Attachment: &models.Attachment{Type: models.AttachmentDocument, FileKey: "someone-elses.pdf"},
+	})
+	require.Error(t, err)
+}
+
+func TestLastDelivery(t *testing.T) {
+	snooze := [][]models.Button{{{Text: "Later", Action: models.ActionSnooze, Snooze: "10m"}}}
+	next := &models.Occurrence{NextTime: "2026-02-14T15:00:00+03:00", Outbox: &models.OutboxMessage{}}
+
+	cases := []struct {
+		name       string
+		nf         *models.Notification
+		occurrence *models.Occurrence
+		sent       bool
+		exp        bool
+	}{
+		{name: "one-off sent", nf: &models.Notification{}, sent: true, exp: true},
+		{name: "one-off sent with snooze", nf: &models.Notification{Buttons: snooze}, sent: true, exp: false},
+		{name: "one-off failed with snooze", nf: &models.Notification{Buttons: snooze}, sent: false, exp: true},
+		{name: "recurring with next occurrence", nf: &models.Notification{Schedule: "0 9 * * *"}, occurrence: next, sent: true, exp: false},
+		{name: "recurring finished", nf: &models.Notification{Schedule: "0 9 * * *"}, occurrence: &models.Occurrence{}, sent: false, exp: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.exp, lastDelivery(tc.nf, tc.occurrence, tc.sent))
+		})
+	}
+}
+
+func TestDelayedNotifierService_DeleteNotificationReleasesAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+	storage := servicemocks.NewMockAttachmentStorageInterface(ctrl)
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		storage: storage,
+		redis:   redisClient,
+		ctx:     setupTestContext(),
+	}
+
+	repo.EXPECT().CancelNotification("test-id").Return(nil).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), "notification:cancelled:test-id", "cancelled", gomock.Any()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), "notification:record:test-id").Return(nil).Times(1)
+	repo.EXPECT().GetNotification("test-id").Return(&models.Notification{
+		Id:         "test-id",
+		Status:     models.StatusCancelled,
+		Attachment: &models.Attachment{Type: models.AttachmentDocument, FileKey: "key.pdf"},
+	}, nil).Times(1)
+	storage.EXPECT().Delete("key.pdf").Return(nil).Times(1)
+
+	require.NoError(t, srv.DeleteNotification("test-id"))
+}
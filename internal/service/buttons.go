@@ -0,0 +1,198 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/telegram"
+	"DelayedNotifier/pkg/logger"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func (service *DelayedNotifierService) validateButtons(nf *models.Notification) error {
+	if len(nf.Buttons) == 0 {
+		return nil
+	}
+	sender, err := service.senders.Get(nf.Channel)
+	if err != nil {
+		return err
+	}
+	if !sender.Capabilities().Buttons {
+		return fmt.Errorf("%s channel does not support buttons", nf.Channel)
+	}
+
+	for i, row := range nf.Buttons {
+		if len(row) == 0 {
+			return fmt.Errorf("button row %d is empty", i)
+		}
+		for j, button := range row {
+			if err := validateButton(nf, button); err != nil {
+				return fmt.Errorf("invalid button %d.%d: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateButton also rejects callback buttons that could never work when
+// pressed: buttons only appear on a delivered message, so cancel needs a
+// recurring notification with an occurrence left to cancel, and snooze, like
+// HandleCallback, a one-off one.
+func validateButton(nf *models.Notification, button models.Button) error {
+	if button.Text == "" {
+		return errors.New("text is required")
+	}
+
+	if button.URL != "" {
+		if button.Action != "" || button.Snooze != "" {
+			return errors.New("url and action are mutually exclusive")
+		}
+		u, err := url.Parse(button.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "tg") {
+			return fmt.Errorf("invalid url: %s", button.URL)
+		}
+		return nil
+	}
+
+	switch button.Action {
+	case models.ActionAck, models.ActionCancel:
+		if button.Snooze != "" {
+			return errors.New("snooze is only allowed for the snooze action")
+		}
+		if button.Action == models.ActionCancel && nf.Schedule == "" {
+			return errors.New("cancel is only available for recurring notifications")
+		}
+	case models.ActionSnooze:
+		if nf.Schedule != "" {
+			return errors.New("recurring notifications cannot be snoozed")
+		}
+		if d, err := time.ParseDuration(button.Snooze); err != nil || d <= 0 {
+			return fmt.Errorf("invalid snooze %q (use a positive duration such as 10m)", button.Snooze)
+		}
+	case "":
+		return errors.New("url or action is required")
+	default:
+		return fmt.Errorf("unsupported action %q (use ack, snooze or cancel)", button.Action)
+	}
+
+	_, err := telegram.EncodeCallback(button.Action, nf.Id, button.Snooze)
+	return err
+}
+
+// telegramKeyboard turns the buttons of nf into an inline keyboard whose
+// callback buttons carry the notification id. Buttons are validated at
+// creation, so encoding cannot fail here.
+func telegramKeyboard(nf *models.Notification) [][]telegram.Button {
+	if len(nf.Buttons) == 0 {
+		return nil
+	}
+	keyboard := make([][]telegram.Button, 0, len(nf.Buttons))
+	for _, row := range nf.Buttons {
+		buttons := make([]telegram.Button, 0, len(row))
+		for _, button := range row {
+			b := telegram.Button{Text: button.Text, URL: button.URL}
+			if button.URL == "" {
+				b.Data, _ = telegram.EncodeCallback(button.Action, nf.Id, button.Snooze)
+			}
+			buttons = append(buttons, b)
+		}
+		keyboard = append(keyboard, buttons)
+	}
+	return keyboard
+}
+
+func hasAction(nf *models.Notification, action string) bool {
+	for _, row := range nf.Buttons {
+		for _, button := range row {
+			if button.Action == action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HandleCallback applies a button pressed under a delivered notification.
+// Only presses from the chat the notification was sent to are accepted.
+func (service *DelayedNotifierService) HandleCallback(cb telegram.Callback) (string, error) {
+	nf, err := service.repo.GetNotification(cb.NotificationId)
+	if err != nil {
+		return "", err
+	}
+	if nf.ChatId != cb.ChatId {
+		return "", models.Invalid(fmt.Errorf("notification %s was not sent to this chat", nf.Id))
+	}
+
+	switch cb.Action {
+	case models.ActionAck:
+		if err := service.AcknowledgeNotification(nf.Id); err != nil {
+			return "", err
+		}
+		// The keyboard goes away with the press, so an acknowledged one-off
+		// notification can no longer be snoozed.
+		if nf.Schedule == "" && nf.Status == models.StatusSent {
+			service.releaseAttachment(nf)
+		}
+		return "Done", nil
+	case models.ActionSnooze:
+		if nf.Schedule != "" {
+			return "", models.Invalid(errors.New("recurring notifications cannot be snoozed"))
+		}
+		delay, err := time.ParseDuration(cb.Arg)
+		if err != nil || delay <= 0 {
+			return "", models.Invalid(fmt.Errorf("invalid snooze %q", cb.Arg))
+		}
+		if _, err := service.SnoozeNotification(nf.Id, delay); err != nil {
+			return "", err
+		}
+		return "Snoozed for " + cb.Arg, nil
+	case models.ActionCancel:
+		if err := service.DeleteNotification(nf.Id); err != nil {
+			return "", err
+		}
+		return "Cancelled", nil
+	default:
+		return "", models.Invalid(fmt.Errorf("unsupported action %q", cb.Action))
+	}
+}
+
+func (service *DelayedNotifierService) AcknowledgeNotification(id string) error {
+	if id == "" {
+		return errors.New("invalid id")
+	}
+	if err := service.repo.AcknowledgeNotification(id, time.Now().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	service.forgetNotification(id)
+
+	logger.GetLoggerFromCtx(service.ctx).Info("Notification acknowledged",
+		zap.String("notification_id", id))
+	return nil
+}
+
+// SnoozeNotification sends a delivered one-off notification again after delay.
+func (service *DelayedNotifierService) SnoozeNotification(id string, delay time.Duration) (*models.Notification, error) {
+	if id == "" {
+		return nil, errors.New("invalid id")
+	}
+
+	sendAt := time.Now().Add(delay)
+	nf, err := service.repo.SnoozeNotification(id, sendAt.Format(time.RFC3339), &models.OutboxMessage{
+		RoutingKey: service.cfg.GetString("ROUTING_KEY"),
+		SendAt:     sendAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	service.forgetNotification(id)
+
+	logger.GetLoggerFromCtx(service.ctx).Info("Notification snoozed",
+		zap.String("notification_id", id),
+		zap.Int("version", nf.Version),
+		zap.Time("send_at", sendAt))
+	return nf, nil
+}
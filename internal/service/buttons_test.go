@@ -0,0 +1,189 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/repository/mocks"
+	servicemocks "DelayedNotifier/internal/service/mocks"
+	"DelayedNotifier/internal/telegram"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/config"
+	"go.uber.org/mock/gomock"
+)
+
+func TestValidateButtons(t *testing.T) {
+	srv := &DelayedNotifierService{senders: testSenders()}
+
+	cases := []struct {
+		name     string
+		channel  string
+		schedule string
+		buttons  [][]models.Button
+		expErr   string
+	}{
+		{
+			name:    "link and callbacks",
+			channel: models.ChannelTelegram,
+			buttons: [][]models.Button{
+				{{Text: "Open", URL: "https://example.com/task/1"}},
+				{{Text: "Done", Action: models.ActionAck}, {Text: "Snooze 10m", Action: models.ActionSnooze, Snooze: "10m"}},
+			},
+		},
+		{
+			name:    "email channel",
+			channel: models.ChannelEmail,
+			buttons: [][]models.Button{{{Text: "Done", Action: models.ActionAck}}},
+			expErr:  "email channel does not support buttons",
+		},
+		{
+			name:    "empty row",
+			channel: models.ChannelTelegram,
+			buttons: [][]models.Button{{}},
+			expErr:  "button row 0 is empty",
+		},
+		{
+			name:    "missing text",
+			channel: models.ChannelTelegram,
+			buttons: [][]models.Button{{{Action: models.ActionAck}}},
+			expErr:  "invalid button 0.0: text is required",
+		},
+		{
+			name:    "url and action",
+			channel: models.ChannelTelegram,
+			buttons: [][]models.Button{{{Text: "Open", URL: "https://example.com", Action: models.ActionAck}}},
+			expErr:  "mutually exclusive",
+		},
+		{
+			name:    "bad url",
+			channel: models.ChannelTelegram,
+			buttons: [][]models.Button{{{Text: "Open", URL: "javascript:alert(1)"}}},
+			expErr:  "invalid url",
+		},
+		{
+			name:    "snooze without delay",
+			channel: models.ChannelTelegram,
+			buttons: [][]models.Button{{{Text: "Later", Action: models.ActionSnooze}}},
+			expErr:  "invalid snooze",
+		},
+		{
+			name:     "cancel on recurring",
+			channel:  models.ChannelTelegram,
+			schedule: "every 1h",
+			buttons:  [][]models.Button{{{Text: "Stop", Action: models.ActionCancel}}},
+		},
+		{
+			name:    "cancel on one-off",
+			channel: models.ChannelTelegram,
+			buttons: [][]models.Button{{{Text: "Stop", Action: models.ActionCancel}}},
+			expErr:  "cancel is only available for recurring notifications",
+		},
+		{
+			name:     "snooze on recurring",
+			channel:  models.ChannelTelegram,
+			schedule: "every 1h",
+			buttons:  [][]models.Button{{{Text: "Later", Action: models.ActionSnooze, Snooze: "10m"}}},
+			expErr:   "recurring notifications cannot be snoozed",
+		},
+		{
+			name:    "unknown action",
+			channel: models.ChannelTelegram,
+			buttons: [][]models.Button{{{Text: "Delete", Action: "delete"}}},
+			expErr:  `unsupported action "delete"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nf := &models.Notification{Id: "6f1c1a52-3c4b-4f7e-9a55-2b0c6d7e8f90", Channel: tc.channel, Schedule: tc.schedule, Buttons: tc.buttons}
+			err := srv.validateButtons(nf)
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expErr)
+		})
+	}
+}
+
+func TestTelegramSenderKeyboardOnLastPart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := servicemocks.NewMockTelegramClientInterface(ctrl)
+	sender := NewTelegramSender(client, nil, nil)
+
+	first := strings.Repeat("a", 4000)
+	second := strings.Repeat("b", 200)
+	nf := &models.Notification{
+		Id:      "test-id",
+		ChatId:  42,
+		Message: first + "\n\n" + second,
+		Split:   true,
+		Buttons: [][]models.Button{{
+			{Text: "Open", URL: "https://example.com"},
+			{Text: "Snooze", Action: models.ActionSnooze, Snooze: "10m"},
+		}},
+	}
+	keyboard := [][]telegram.Button{{
+		{Text: "Open", URL: "https://example.com"},
+		{Text: "Snooze", Data: "snooze:test-id:10m"},
+	}}
+
+	gomock.InOrder(
+		client.EXPECT().SendMessage(int64(42), first, "", gomock.Nil()).Return(1, nil),
+		client.EXPECT().SendMessage(int64(42), second, "", keyboard).Return(2, nil),
+	)
+	ids, err := sender.Send(nf)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, ids)
+}
+
+func TestDelayedNotifierService_HandleCallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+	cfg := config.New()
+	cfg.EnableEnv("")
+	cfg.SetDefault("ROUTING_KEY", "test.routing.key")
+
+	srv := &DelayedNotifierService{
+		repo:  repo,
+		redis: redisClient,
+		ctx:   setupTestContext(),
+		cfg:   cfg,
+	}
+	nf := &models.Notification{Id: "test-id", ChatId: 42, Status: "sent"}
+
+	repo.EXPECT().GetNotification("test-id").Return(nf, nil).Times(4)
+
+	repo.EXPECT().AcknowledgeNotification("test-id", gomock.Any()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), "notification:record:test-id").Return(nil).Times(1)
+	text, err := srv.HandleCallback(telegram.Callback{Action: models.ActionAck, NotificationId: "test-id", ChatId: 42})
+	require.NoError(t, err)
+	require.Equal(t, "Done", text)
+
+	_, err = srv.HandleCallback(telegram.Callback{Action: models.ActionAck, NotificationId: "test-id", ChatId: 7})
+	require.EqualError(t, err, "notification test-id was not sent to this chat")
+
+	before := time.Now()
+	repo.EXPECT().SnoozeNotification("test-id", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(id string, sendTime string, outbox *models.OutboxMessage) (*models.Notification, error) {
+			require.Equal(t, "test.routing.key", outbox.RoutingKey)
+			require.WithinDuration(t, before.Add(10*time.Minute), outbox.SendAt, 5*time.Second)
+			return &models.Notification{Id: id, Time: sendTime, Status: "created", Version: 1}, nil
+		}).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	text, err = srv.HandleCallback(telegram.Callback{Action: models.ActionSnooze, NotificationId: "test-id", Arg: "10m", ChatId: 42})
+	require.NoError(t, err)
+	require.Equal(t, "Snoozed for 10m", text)
+
+	nf.Schedule = "@daily"
+	_, err = srv.HandleCallback(telegram.Callback{Action: models.ActionSnooze, NotificationId: "test-id", Arg: "10m", ChatId: 42})
+	require.EqualError(t, err, "recurring notifications cannot be snoozed")
+}
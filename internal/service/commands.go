@@ -0,0 +1,230 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/telegram"
+	"DelayedNotifier/pkg/logger"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// shortIdLength is how much of a notification id /list shows and /cancel
+	// needs at least.
+	shortIdLength   = 8
+	listLimit       = 30
+	listPreviewSize = 40
+	commandTime     = "Mon 02 Jan 15:04"
+)
+
+const commandHelp = `I can remind you about anything.
+
+/remind 2h Call mom - in 2 hours
+/remind 18:30 Take the pills - today or tomorrow at 18:30
+/remind 2026-03-08T09:00 Buy flowers - at a date and time
+/list - pending reminders
+/cancel <id> - cancel a reminder
+/timezone Europe/Moscow - set your timezone (UTC by default)`
+
+// HandleCommand runs a bot command sent from a Telegram chat. Reminders are
+// created for, and only visible to, the chat the command came from.
+func (service *DelayedNotifierService) HandleCommand(cmd telegram.Command) (string, error) {
+	switch cmd.Name {
+	case "start", "help":
+		return commandHelp, nil
+	case "remind":
+		return service.remindCommand(cmd)
+	case "list":
+		return service.listCommand(cmd)
+	case "cancel":
+		return service.cancelCommand(cmd)
+	case "timezone":
+		return service.timezoneCommand(cmd)
+	default:
+		return "", models.Invalid(fmt.Errorf("unknown command /%s, see /help", cmd.Name))
+	}
+}
+
+func (service *DelayedNotifierService) remindCommand(cmd telegram.Command) (string, error) {
+	when, text, _ := strings.Cut(cmd.Args, " ")
+	text = strings.TrimSpace(text)
+	if when == "" || text == "" {
+		return "", models.Invalid(errors.New("usage: /remind <2h|18:30|2026-03-08T09:00> <text>"))
+	}
+
+	loc := service.chatLocation(cmd.ChatId)
+	at, err := parseRemindTime(when, time.Now().In(loc))
+	if err != nil {
+		return "", models.Invalid(err)
+	}
+
+	nf := &models.Notification{
+		Message: text,
+		Time:    at.Format(time.RFC3339),
+		ChatId:  cmd.ChatId,
+		Channel: models.ChannelTelegram,
+	}
+	id, err := service.CreateNotification(nf)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Reminder %s set for %s", shortId(id), at.Format(commandTime)), nil
+}
+
+// parseRemindTime accepts a delay such as "2h", a time of day such as "18:30"
+// (the next one after now) or a date and time such as "2026-03-08T09:00", all
+// in the location of now.
+func parseRemindTime(when string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(when); err == nil {
+		if d <= 0 {
+			return time.Time{}, errors.New("the delay must be positive")
+		}
+		return now.Add(d), nil
+	}
+
+	if t, err := time.ParseInLocation("15:04", when, now.Location()); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+
+	if at, err := time.ParseInLocation("2006-01-02T15:04", when, now.Location()); err == nil {
+		if !at.After(now) {
+			return time.Time{}, errors.New("this time has already passed")
+		}
+		return at, nil
+	}
+
+	return time.Time{}, fmt.Errorf("cannot understand %q, use 2h, 18:30 or 2026-03-08T09:00", when)
+}
+
+func (service *DelayedNotifierService) listCommand(cmd telegram.Command) (string, error) {
+	pending, err := service.pendingReminders(cmd.ChatId)
+	if err != nil {
+		return "", err
+	}
+	if len(pending) == 0 {
+		return "You have no pending reminders", nil
+	}
+
+	loc := service.chatLocation(cmd.ChatId)
+	var b strings.Builder
+	for i, nf := range pending {
+		if i == listLimit {
+			fmt.Fprintf(&b, "...and %d more\n", len(pending)-listLimit)
+			break
+		}
+		when := nf.Time
+		if t, err := time.Parse(time.RFC3339, nf.Time); err == nil {
+			when = t.In(loc).Format(commandTime)
+		}
+		fmt.Fprintf(&b, "%s  %s  %s\n", shortId(nf.Id), when, preview(nf.Message))
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func (service *DelayedNotifierService) cancelCommand(cmd telegram.Command) (string, error) {
+	prefix := strings.TrimSpace(cmd.Args)
+	if prefix == "" {
+		return "", models.Invalid(errors.New("usage: /cancel <id>, see /list for ids"))
+	}
+	if utf8.RuneCountInString(prefix) < shortIdLength {
+		return "", models.Invalid(fmt.Errorf("the id must have at least %d characters", shortIdLength))
+	}
+
+	pending, err := service.pendingReminders(cmd.ChatId)
+	if err != nil {
+		return "", err
+	}
+	var matches []*models.Notification
+	for _, nf := range pending {
+		if strings.HasPrefix(nf.Id, prefix) {
+			matches = append(matches, nf)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", models.Invalid(fmt.Errorf("no pending reminder %s, see /list", prefix))
+	case 1:
+	default:
+		return "", models.Invalid(fmt.Errorf("%s matches several reminders, use more characters of the id", prefix))
+	}
+
+	if err := service.DeleteNotification(matches[0].Id); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Reminder %s cancelled", shortId(matches[0].Id)), nil
+}
+
+func (service *DelayedNotifierService) timezoneCommand(cmd telegram.Command) (string, error) {
+	name := strings.TrimSpace(cmd.Args)
+	if name == "" {
+		return fmt.Sprintf("Your timezone is %s", service.chatLocation(cmd.ChatId)), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return "", models.Invalid(fmt.Errorf("unknown timezone %q, use a name such as Europe/Moscow", name))
+	}
+	if err := service.repo.SetChatTimezone(cmd.ChatId, loc.String()); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Timezone set to %s, now %s", loc, time.Now().In(loc).Format("15:04")), nil
+}
+
+// pendingReminders returns the chat's notifications waiting to be sent,
+// soonest first.
+func (service *DelayedNotifierService) pendingReminders(chatId int64) ([]*models.Notification, error) {
+	pending, err := service.repo.GetPendingNotifications(chatId)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		ti, _ := time.Parse(time.RFC3339, pending[i].Time)
+		tj, _ := time.Parse(time.RFC3339, pending[j].Time)
+		return ti.Before(tj)
+	})
+	return pending, nil
+}
+
+// chatLocation returns the timezone set for the chat, falling back to UTC.
+func (service *DelayedNotifierService) chatLocation(chatId int64) *time.Location {
+	name, err := service.repo.GetChatTimezone(chatId)
+	if err != nil {
+		logger.GetLoggerFromCtx(service.ctx).Warn("Failed to get chat timezone, using UTC",
+			zap.Error(err),
+			zap.Int64("chat_id", chatId))
+		return time.UTC
+	}
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func shortId(id string) string {
+	if len(id) > shortIdLength {
+		return id[:shortIdLength]
+	}
+	return id
+}
+
+func preview(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if utf8.RuneCountInString(message) <= listPreviewSize {
+		return message
+	}
+	return string([]rune(message)[:listPreviewSize-1]) + "…"
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/repository/mocks"
+	servicemocks "DelayedNotifier/internal/service/mocks"
+	"DelayedNotifier/internal/telegram"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/config"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseRemindTime(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 7, 20, 0, 0, 0, moscow)
+
+	cases := []struct {
+		when   string
+		exp    time.Time
+		expErr string
+	}{
+		{when: "2h", exp: now.Add(2 * time.Hour)},
+		{when: "1h30m", exp: now.Add(90 * time.Minute)},
+		{when: "21:15", exp: time.Date(2026, 3, 7, 21, 15, 0, 0, moscow)},
+		{when: "09:00", exp: time.Date(2026, 3, 8, 9, 0, 0, 0, moscow)},
+		{when: "2026-03-08T09:00", exp: time.Date(2026, 3, 8, 9, 0, 0, 0, moscow)},
+		{when: "2026-03-01T09:00", expErr: "already passed"},
+		{when: "-5m", expErr: "must be positive"},
+		{when: "tomorrow", expErr: `cannot understand "tomorrow"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.when, func(t *testing.T) {
+			at, err := parseRemindTime(tc.when, now)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.exp.Equal(at), "expected %s, got %s", tc.exp, at)
+		})
+	}
+}
+
+func newCommandTestService(ctrl *gomock.Controller) (*DelayedNotifierService, *mocks.MockNotificationRepositoryInterface, *servicemocks.MockRedisClientInterface) {
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+	cfg := config.New()
+	cfg.EnableEnv("")
+	cfg.SetDefault("ROUTING_KEY", "test.routing.key")
+
+	return &DelayedNotifierService{
+		repo:    repo,
+		senders: testSenders(),
+		redis:   redisClient,
+		ctx:     setupTestContext(),
+		cfg:     cfg,
+	}, repo, redisClient
+}
+
+func TestDelayedNotifierService_RemindCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv, repo, redisClient := newCommandTestService(ctrl)
+
+	before := time.Now()
+	repo.EXPECT().GetChatTimezone(int64(42)).Return("Europe/Moscow", nil).Times(1)
+	repo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(nf *models.Notification, outbox *models.OutboxMessage) error {
+			require.Equal(t, "Call mom", nf.Message)
+			require.Equal(t, int64(42), nf.ChatId)
+			require.Equal(t, models.ChannelTelegram, nf.Channel)
+			require.WithinDuration(t, before.Add(2*time.Hour), outbox.SendAt, 5*time.Second)
+			return nil
+		}).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	text, err := srv.HandleCommand(telegram.Command{Name: "remind", Args: "2h Call mom", ChatId: 42})
+	require.NoError(t, err)
+	require.Contains(t, text, "Reminder ")
+	require.Contains(t, text, before.Add(2*time.Hour).In(time.FixedZone("MSK", 3*3600)).Format("15:04"))
+
+	_, err = srv.HandleCommand(telegram.Command{Name: "remind", Args: "2h", ChatId: 42})
+	require.ErrorContains(t, err, "usage: /remind")
+	require.True(t, models.IsInvalid(err))
+}
+
+func TestDelayedNotifierService_ListAndCancelCommands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv, repo, redisClient := newCommandTestService(ctrl)
+
+	pending := func() []*models.Notification {
+		return []*models.Notification{
+			{Id: "bbbbbbbb-2222-4000-8000-000000000002", Message: "Water the plants", Time: "2026-03-08T12:00:00Z"},
+			{Id: "aaaaaaaa-1111-4000-8000-000000000001", Message: "Call mom", Time: "2026-03-08T09:00:00Z"},
+			{Id: "aaaaaaaa-3333-4000-8000-000000000003", Message: "Buy flowers", Time: "2026-03-08T10:00:00Z"},
+		}
+	}
+	repo.EXPECT().GetPendingNotifications(int64(42)).DoAndReturn(func(int64) ([]*models.Notification, error) {
+		return pending(), nil
+	}).AnyTimes()
+	repo.EXPECT().GetChatTimezone(int64(42)).Return("", nil).AnyTimes()
+
+	text, err := srv.HandleCommand(telegram.Command{Name: "list", ChatId: 42})
+	require.NoError(t, err)
+	require.Equal(t, "aaaaaaaa  Sun 08 Mar 09:00  Call mom\n"+
+		"aaaaaaaa  Sun 08 Mar 10:00  Buy flowers\n"+
+		"bbbbbbbb  Sun 08 Mar 12:00  Water the plants", text)
+
+	_, err = srv.HandleCommand(telegram.Command{Name: "cancel", Args: "aaaaaaaa", ChatId: 42})
+	require.ErrorContains(t, err, "matches several reminders")
+
+	_, err = srv.HandleCommand(telegram.Command{Name: "cancel", Args: "cccccccc", ChatId: 42})
+	require.ErrorContains(t, err, "no pending reminder cccccccc")
+
+	repo.EXPECT().CancelNotification("aaaaaaaa-3333-4000-8000-000000000003").Return(nil).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), "cancelled", gomock.Any()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	text, err = srv.HandleCommand(telegram.Command{Name: "cancel", Args: "aaaaaaaa-3333", ChatId: 42})
+	require.NoError(t, err)
+	require.Equal(t, "Reminder aaaaaaaa cancelled", text)
+}
+
+func TestDelayedNotifierService_TimezoneCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv, repo, _ := newCommandTestService(ctrl)
+
+	repo.EXPECT().SetChatTimezone(int64(42), "Europe/Moscow").Return(nil).Times(1)
+	text, err := srv.HandleCommand(telegram.Command{Name: "timezone", Args: "Europe/Moscow", ChatId: 42})
+	require.NoError(t, err)
+	require.Contains(t, text, "Timezone set to Europe/Moscow")
+
+	repo.EXPECT().GetChatTimezone(int64(42)).Return("Europe/Moscow", nil).Times(1)
+	text, err = srv.HandleCommand(telegram.Command{Name: "timezone", ChatId: 42})
+	require.NoError(t, err)
+	require.Equal(t, "Your timezone is Europe/Moscow", text)
+
+	_, err = srv.HandleCommand(telegram.Command{Name: "timezone", Args: "Mars/Olympus", ChatId: 42})
+	require.ErrorContains(t, err, `unknown timezone "Mars/Olympus"`)
+
+	_, err = srv.HandleCommand(telegram.Command{Name: "weather", ChatId: 42})
+	require.EqualError(t, err, "unknown command /weather, see /help")
+}
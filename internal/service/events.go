@@ -0,0 +1,25 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"errors"
+)
+
+// GetNotificationEvents returns the status history of a notification, oldest
+// event first. Notifications created before events were recorded have none.
+func (service *DelayedNotifierService) GetNotificationEvents(id string) ([]*models.NotificationEvent, error) {
+	if id == "" {
+		return nil, errors.New("invalid id")
+	}
+	events, err := service.repo.GetNotificationEvents(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		// Tell an unknown id apart from a notification without history.
+		if _, err := service.repo.GetNotification(id); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/repository/mocks"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDelayedNotifierService_GetNotificationEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	srv := &DelayedNotifierService{repo: repo, ctx: setupTestContext()}
+
+	history := []*models.NotificationEvent{
+		{Id: 1, NotificationId: "test-id", Event: models.EventCreated, Status: "created"},
+		{Id: 2, NotificationId: "test-id", Event: models.EventSending, Status: "sending", Attempt: 1, WorkerId: "worker-1"},
+		{Id: 3, NotificationId: "test-id", Event: models.EventRetryScheduled, Status: "created", Attempt: 1, WorkerId: "worker-1", Error: "Bad Gateway"},
+	}
+	repo.EXPECT().GetNotificationEvents("test-id").Return(history, nil).Times(1)
+	repo.EXPECT().GetNotification(gomock.Any()).Times(0)
+
+	events, err := srv.GetNotificationEvents("test-id")
+	require.NoError(t, err)
+	require.Equal(t, history, events)
+}
+
+func TestDelayedNotifierService_GetNotificationEventsWithoutHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	srv := &DelayedNotifierService{repo: repo, ctx: setupTestContext()}
+
+	// A notification created before events were recorded.
+	repo.EXPECT().GetNotificationEvents("old-id").Return([]*models.NotificationEvent{}, nil).Times(1)
+	repo.EXPECT().GetNotification("old-id").Return(&models.Notification{Id: "old-id"}, nil).Times(1)
+	events, err := srv.GetNotificationEvents("old-id")
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	repo.EXPECT().GetNotificationEvents("missing").Return([]*models.NotificationEvent{}, nil).Times(1)
+	repo.EXPECT().GetNotification("missing").Return(nil, errors.New("notification not found: missing")).Times(1)
+	_, err = srv.GetNotificationEvents("missing")
+	require.EqualError(t, err, "notification not found: missing")
+
+	_, err = srv.GetNotificationEvents("")
+	require.EqualError(t, err, "invalid id")
+}
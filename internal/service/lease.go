@@ -0,0 +1,155 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/pkg/logger"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDeliveryLease      = 5 * time.Minute
+	defaultLeaseCheckInterval = 30 * time.Second
+	leaseRecoveryBatchSize    = 100
+)
+
+var errLeaseExpired = errors.New("delivery lease expired")
+
+// deliveryLease is how long a worker may go without renewing the lease on a
+// notification it is sending before other workers assume it died. The lease
+// is renewed every third of it, so it only has to outlast a stalled renewal.
+func deliveryLease(cfg *config.Config) time.Duration {
+	lease := time.Duration(cfg.GetInt("DELIVERY_LEASE_MS")) * time.Millisecond
+	if lease <= 0 {
+		lease = defaultDeliveryLease
+	}
+	return lease
+}
+
+// RecoverExpiredLeases handles notifications whose worker claimed them but
+// stopped renewing the lease without finishing, usually because the process
+// died mid-delivery. The message may already have reached the recipient, so
+// each one is marked failed for review instead of being sent again; a
+// recurring notification still moves on to its next occurrence.
+func (service *DelayedNotifierService) RecoverExpiredLeases() (int, error) {
+	expired, err := service.repo.GetExpiredLeases(leaseRecoveryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for _, nf := range expired {
+		logger.GetLoggerFromCtx(service.ctx).Warn("Delivery lease expired",
+			zap.String("notification_id", nf.Id),
+			zap.Int("attempt", nf.Attempts))
+
+		// Another replica may recover the same notification, or the worker
+		// may finish late; the conditional updates let only one of them win.
+		if err := service.recoverLease(nf); err != nil {
+			logger.GetLoggerFromCtx(service.ctx).Info("Expired lease not recovered",
+				zap.Error(err),
+				zap.String("notification_id", nf.Id))
+			continue
+		}
+		recovered++
+	}
+	return recovered, nil
+}
+
+func (service *DelayedNotifierService) recoverLease(nf *models.Notification) error {
+	occurrence := service.finishOccurrence(nf)
+	if err := service.repo.MarkNotificationFailed(nf.Id, nf.Version, errLeaseExpired.Error(), occurrence); err != nil {
+		return err
+	}
+	service.forgetNotification(nf.Id)
+	if lastDelivery(nf, occurrence, false) {
+		service.releaseAttachment(nf)
+	}
+	return nil
+}
+
+// keepLease renews the lease on nf every third of its length until the
+// returned function is called, so a slow but live delivery is never taken for
+// a dead one. The returned function waits for the renewals to stop.
+func (service *DelayedNotifierService) keepLease(nf *models.Notification) func() {
+	if service.lease <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(service.lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				extended, err := service.repo.ExtendLease(nf.Id, nf.Version, service.lease)
+				if err != nil {
+					logger.GetLoggerFromCtx(service.ctx).Warn("Failed to extend delivery lease",
+						zap.Error(err),
+						zap.String("notification_id", nf.Id))
+					continue
+				}
+				if !extended {
+					logger.GetLoggerFromCtx(service.ctx).Warn("Delivery lease lost",
+						zap.String("notification_id", nf.Id),
+						zap.Int("version", nf.Version))
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// LeaseRecovery periodically recovers notifications with expired delivery
+// leases. Every replica may run it.
+type LeaseRecovery struct {
+	service  *DelayedNotifierService
+	interval time.Duration
+}
+
+func NewLeaseRecovery(service *DelayedNotifierService, cfg *config.Config) *LeaseRecovery {
+	interval := time.Duration(cfg.GetInt("DELIVERY_LEASE_CHECK_INTERVAL_MS")) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultLeaseCheckInterval
+	}
+	return &LeaseRecovery{service: service, interval: interval}
+}
+
+func (r *LeaseRecovery) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				recovered, err := r.service.RecoverExpiredLeases()
+				if err != nil {
+					logger.GetLoggerFromCtx(ctx).Error("Lease recovery failed",
+						zap.Error(err))
+					break
+				}
+				if recovered < leaseRecoveryBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
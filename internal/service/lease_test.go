@@ -0,0 +1,158 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/repository/mocks"
+	servicemocks "DelayedNotifier/internal/service/mocks"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/config"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeliveryLease(t *testing.T) {
+	cfg := config.New()
+	require.Equal(t, defaultDeliveryLease, deliveryLease(cfg))
+
+	cfg.SetDefault("DELIVERY_LEASE_MS", 90000)
+	require.Equal(t, 90*time.Second, deliveryLease(cfg))
+}
+
+func TestDelayedNotifierService_RecoverExpiredLeasesMarksFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	expired := &models.Notification{
+		Id:       "test-id",
+		Time:     time.Now().Format(time.RFC3339),
+		Status:   models.StatusSending,
+		Attempts: 1,
+		Version:  4,
+	}
+
+	// The message may have been delivered before the worker died, so it is
+	// not resent even though the retry policy has attempts left.
+	repo.EXPECT().GetExpiredLeases(leaseRecoveryBatchSize).Return([]*models.Notification{expired}, nil).Times(1)
+	repo.EXPECT().ScheduleRetry(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	repo.EXPECT().MarkNotificationFailed("test-id", 4, errLeaseExpired.Error(), gomock.Nil()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), "notification:record:test-id").Return(nil).Times(1)
+
+	srv := &DelayedNotifierService{
+		repo:        repo,
+		redis:       redisClient,
+		ctx:         setupTestContext(),
+		cfg:         config.New(),
+		retryPolicy: RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Second, MaxAge: time.Hour},
+	}
+
+	recovered, err := srv.RecoverExpiredLeases()
+	require.NoError(t, err)
+	require.Equal(t, 1, recovered)
+}
+
+func TestDelayedNotifierService_RecoverExpiredLeasesMarksFailedWhenExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	expired := &models.Notification{
+		Id:       "test-id",
+		Time:     time.Now().Format(time.RFC3339),
+		Status:   models.StatusSending,
+		Attempts: 3,
+		Version:  6,
+	}
+
+	repo.EXPECT().GetExpiredLeases(leaseRecoveryBatchSize).Return([]*models.Notification{expired}, nil).Times(1)
+	repo.EXPECT().ScheduleRetry(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	repo.EXPECT().MarkNotificationFailed("test-id", 6, errLeaseExpired.Error(), gomock.Nil()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), "notification:record:test-id").Return(nil).Times(1)
+
+	srv := &DelayedNotifierService{
+		repo:        repo,
+		redis:       redisClient,
+		ctx:         setupTestContext(),
+		cfg:         config.New(),
+		retryPolicy: RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Second, MaxAge: time.Hour},
+	}
+
+	recovered, err := srv.RecoverExpiredLeases()
+	require.NoError(t, err)
+	require.Equal(t, 1, recovered)
+}
+
+func TestDelayedNotifierService_RecoverExpiredLeasesSkipsConflicts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	now := time.Now().Format(time.RFC3339)
+	first := &models.Notification{Id: "first", Time: now, Status: models.StatusSending, Attempts: 3, Version: 1}
+	second := &models.Notification{Id: "second", Time: now, Status: models.StatusSending, Attempts: 3, Version: 2}
+
+	repo.EXPECT().GetExpiredLeases(leaseRecoveryBatchSize).Return([]*models.Notification{first, second}, nil).Times(1)
+	repo.EXPECT().MarkNotificationFailed("first", 1, gomock.Any(), gomock.Nil()).Return(errors.New("notification first changed concurrently")).Times(1)
+	repo.EXPECT().MarkNotificationFailed("second", 2, gomock.Any(), gomock.Nil()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), "notification:record:second").Return(nil).Times(1)
+
+	srv := &DelayedNotifierService{
+		repo:        repo,
+		redis:       redisClient,
+		ctx:         setupTestContext(),
+		cfg:         config.New(),
+		retryPolicy: RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Second, MaxAge: time.Hour},
+	}
+
+	recovered, err := srv.RecoverExpiredLeases()
+	require.NoError(t, err)
+	require.Equal(t, 1, recovered)
+}
+
+func TestDelayedNotifierService_RecoverExpiredLeasesRepositoryError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	repo.EXPECT().GetExpiredLeases(leaseRecoveryBatchSize).Return(nil, errors.New("database error")).Times(1)
+
+	srv := &DelayedNotifierService{repo: repo, ctx: setupTestContext()}
+
+	_, err := srv.RecoverExpiredLeases()
+	require.Error(t, err)
+}
+
+func TestDelayedNotifierService_KeepLease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	srv := &DelayedNotifierService{repo: repo, ctx: setupTestContext(), lease: 30 * time.Millisecond}
+	nf := &models.Notification{Id: "test-id", Version: 4}
+
+	extended := make(chan struct{}, 10)
+	repo.EXPECT().ExtendLease("test-id", 4, 30*time.Millisecond).
+		DoAndReturn(func(string, int, time.Duration) (bool, error) {
+			extended <- struct{}{}
+			return true, nil
+		}).MinTimes(1)
+
+	stop := srv.keepLease(nf)
+	<-extended
+	stop()
+
+	// Renewals end once the lease is lost.
+	repo.EXPECT().ExtendLease("test-id", 5, 30*time.Millisecond).Return(false, nil).Times(1)
+	stop = srv.keepLease(&models.Notification{Id: "test-id", Version: 5})
+	time.Sleep(50 * time.Millisecond)
+	stop()
+}
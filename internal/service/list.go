@@ -0,0 +1,119 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// ListNotifications returns a page of the notifications matching filter,
+// latest send time first unless filter.Sort is "time". The page's
+// NextCursor, passed back as filter.Cursor, fetches the next page.
+func (service *DelayedNotifierService) ListNotifications(filter *models.NotificationFilter) (*models.NotificationPage, error) {
+	q, err := listQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, total, err := service.repo.ListNotifications(q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.NotificationPage{Notifications: notifications, Total: total}
+	if len(notifications) > q.Limit {
+		page.Notifications = notifications[:q.Limit]
+		last := page.Notifications[q.Limit-1]
+		page.NextCursor = encodeCursor(last.SendAt, last.Id)
+	}
+	if page.Notifications == nil {
+		page.Notifications = []*models.Notification{}
+	}
+	return page, nil
+}
+
+func listQuery(filter *models.NotificationFilter) (*models.NotificationQuery, error) {
+	q := &models.NotificationQuery{
+		ChatId:  filter.ChatId,
+		Channel: filter.Channel,
+		Limit:   filter.Limit,
+	}
+
+	for _, name := range strings.Split(filter.Status, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			status, err := models.ParseStatus(name)
+			if err != nil {
+				return nil, models.Invalid(err)
+			}
+			q.Statuses = append(q.Statuses, status)
+		}
+	}
+
+	var err error
+	if filter.From != "" {
+		if q.From, err = time.Parse(time.RFC3339, filter.From); err != nil {
+			return nil, models.Invalid(fmt.Errorf("invalid from (use RFC3339): %w", err))
+		}
+	}
+	if filter.To != "" {
+		if q.To, err = time.Parse(time.RFC3339, filter.To); err != nil {
+			return nil, models.Invalid(fmt.Errorf("invalid to (use RFC3339): %w", err))
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.To.After(q.From) {
+		return nil, models.Invalid(errors.New("to must be after from"))
+	}
+
+	switch filter.Sort {
+	case "", "-time":
+		q.Desc = true
+	case "time":
+	default:
+		return nil, models.Invalid(fmt.Errorf("unsupported sort %q (use time or -time)", filter.Sort))
+	}
+
+	if q.Limit == 0 {
+		q.Limit = defaultListLimit
+	}
+	if q.Limit < 0 || q.Limit > maxListLimit {
+		return nil, models.Invalid(fmt.Errorf("limit must be between 1 and %d", maxListLimit))
+	}
+
+	if filter.Cursor != "" {
+		if q.AfterSendAt, q.AfterId, err = decodeCursor(filter.Cursor); err != nil {
+			return nil, models.Invalid(err)
+		}
+	}
+	return q, nil
+}
+
+// A cursor is the send time and id of the last notification of a page, so
+// the next page starts right after it however many rows were added since.
+func encodeCursor(sendAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(sendAt.UnixMicro(), 10) + ":" + id))
+}
+
+func decodeCursor(cursor string) (time.Time, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", errors.New("invalid cursor")
+	}
+	micros, id, ok := strings.Cut(string(data), ":")
+	if !ok || id == "" {
+		return time.Time{}, "", errors.New("invalid cursor")
+	}
+	n, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, "", errors.New("invalid cursor")
+	}
+	return time.UnixMicro(n), id, nil
+}
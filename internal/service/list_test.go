@@ -0,0 +1,84 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListQuery(t *testing.T) {
+	q, err := listQuery(&models.NotificationFilter{
+		Status:  "created, sending,",
+		ChatId:  42,
+		Channel: models.ChannelTelegram,
+		From:    "2026-03-01T00:00:00+03:00",
+		To:      "2026-04-01T00:00:00+03:00",
+		Sort:    "time",
+		Limit:   10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []models.Status{models.StatusCreated, models.StatusSending}, q.Statuses)
+	require.Equal(t, int64(42), q.ChatId)
+	require.Equal(t, models.ChannelTelegram, q.Channel)
+	require.True(t, q.From.Equal(time.Date(2026, 2, 28, 21, 0, 0, 0, time.UTC)))
+	require.True(t, q.To.Equal(time.Date(2026, 3, 31, 21, 0, 0, 0, time.UTC)))
+	require.False(t, q.Desc)
+	require.Equal(t, 10, q.Limit)
+
+	cases := []struct {
+		name   string
+		filter models.NotificationFilter
+		expErr string
+	}{
+		{name: "unknown status", filter: models.NotificationFilter{Status: "created,delivered"}, expErr: `unknown status "delivered"`},
+		{name: "bad from", filter: models.NotificationFilter{From: "yesterday"}, expErr: "invalid from"},
+		{name: "empty range", filter: models.NotificationFilter{From: "2026-03-01T00:00:00Z", To: "2026-03-01T00:00:00Z"}, expErr: "to must be after from"},
+		{name: "bad sort", filter: models.NotificationFilter{Sort: "status"}, expErr: `unsupported sort "status"`},
+		{name: "limit too large", filter: models.NotificationFilter{Limit: maxListLimit + 1}, expErr: "limit must be between 1 and 500"},
+		{name: "bad cursor", filter: models.NotificationFilter{Cursor: "!!"}, expErr: "invalid cursor"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := listQuery(&tc.filter)
+			require.ErrorContains(t, err, tc.expErr)
+			require.True(t, models.IsInvalid(err))
+		})
+	}
+}
+
+func TestDelayedNotifierService_ListNotificationsPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	srv := &DelayedNotifierService{repo: repo, ctx: setupTestContext()}
+
+	sendAt := time.Date(2026, 3, 8, 9, 0, 0, 123000, time.UTC)
+	repo.EXPECT().ListNotifications(&models.NotificationQuery{Desc: true, Limit: 2}).Return([]*models.Notification{
+		{Id: "id-3"},
+		{Id: "id-2", SendAt: sendAt},
+		{Id: "id-1"},
+	}, 3, nil).Times(1)
+
+	page, err := srv.ListNotifications(&models.NotificationFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Notifications, 2)
+	require.Equal(t, 3, page.Total)
+	require.NotEmpty(t, page.NextCursor)
+
+	repo.EXPECT().ListNotifications(gomock.Any()).
+		DoAndReturn(func(q *models.NotificationQuery) ([]*models.Notification, int, error) {
+			require.Equal(t, "id-2", q.AfterId)
+			require.True(t, sendAt.Equal(q.AfterSendAt))
+			return []*models.Notification{{Id: "id-1"}}, 3, nil
+		}).Times(1)
+
+	page, err = srv.ListNotifications(&models.NotificationFilter{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Notifications, 1)
+	require.Empty(t, page.NextCursor)
+}
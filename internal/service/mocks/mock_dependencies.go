@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=service.go -destination=mocks/mock_dependencies.go -package=mocks RabbitMQProducerInterface,TelegramClientInterface,RedisClientInterface
+//	mockgen -source=service.go -destination=mocks/mock_dependencies.go -package=mocks RabbitMQProducerInterface,TelegramClientInterface,TelegramRateLimiterInterface,EmailClientInterface,WebhookClientInterface,RedisClientInterface,AttachmentStorageInterface
 //
 
 // Package mocks is a generated GoMock package.
@@ -11,7 +11,9 @@ package mocks
 
 import (
 	models "DelayedNotifier/internal/models"
+	telegram "DelayedNotifier/internal/telegram"
 	context "context"
+	io "io"
 	reflect "reflect"
 	time "time"
 
@@ -42,76 +44,312 @@ func (m *MockNotificationRepositoryInterface) EXPECT() *MockNotificationReposito
 	return m.recorder
 }
 
+// AcknowledgeNotification mocks base method.
+func (m *MockNotificationRepositoryInterface) AcknowledgeNotification(id, acknowledgedAt string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcknowledgeNotification", id, acknowledgedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcknowledgeNotification indicates an expected call of AcknowledgeNotification.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) AcknowledgeNotification(id, acknowledgedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcknowledgeNotification", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).AcknowledgeNotification), id, acknowledgedAt)
+}
+
+// CancelNotification mocks base method.
+func (m *MockNotificationRepositoryInterface) CancelNotification(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelNotification", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelNotification indicates an expected call of CancelNotification.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) CancelNotification(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelNotification", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).CancelNotification), id)
+}
+
 // CreateNotification mocks base method.
-func (m *MockNotificationRepositoryInterface) CreateNotification(notification *models.Notification) error {
+func (m *MockNotificationRepositoryInterface) CreateNotification(notification *models.Notification, outbox *models.OutboxMessage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateNotification", notification)
+	ret := m.ctrl.Call(m, "CreateNotification", notification, outbox)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateNotification indicates an expected call of CreateNotification.
-func (mr *MockNotificationRepositoryInterfaceMockRecorder) CreateNotification(notification any) *gomock.Call {
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) CreateNotification(notification, outbox any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).CreateNotification), notification, outbox)
+}
+
+// ExtendLease mocks base method.
+func (m *MockNotificationRepositoryInterface) ExtendLease(id string, version int, lease time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendLease", id, version, lease)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtendLease indicates an expected call of ExtendLease.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) ExtendLease(id, version, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendLease", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).ExtendLease), id, version, lease)
+}
+
+// GetChatTimezone mocks base method.
+func (m *MockNotificationRepositoryInterface) GetChatTimezone(chatId int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatTimezone", chatId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatTimezone indicates an expected call of GetChatTimezone.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) GetChatTimezone(chatId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatTimezone", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).GetChatTimezone), chatId)
+}
+
+// GetExpiredLeases mocks base method.
+func (m *MockNotificationRepositoryInterface) GetExpiredLeases(limit int) ([]*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredLeases", limit)
+	ret0, _ := ret[0].([]*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredLeases indicates an expected call of GetExpiredLeases.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) GetExpiredLeases(limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredLeases", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).GetExpiredLeases), limit)
+}
+
+// GetNotification mocks base method.
+func (m *MockNotificationRepositoryInterface) GetNotification(id string) (*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotification", id)
+	ret0, _ := ret[0].(*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotification indicates an expected call of GetNotification.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) GetNotification(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotification", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).GetNotification), id)
+}
+
+// GetNotificationEvents mocks base method.
+func (m *MockNotificationRepositoryInterface) GetNotificationEvents(id string) ([]*models.NotificationEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationEvents", id)
+	ret0, _ := ret[0].([]*models.NotificationEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationEvents indicates an expected call of GetNotificationEvents.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) GetNotificationEvents(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationEvents", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).GetNotificationEvents), id)
+}
+
+// GetPendingNotifications mocks base method.
+func (m *MockNotificationRepositoryInterface) GetPendingNotifications(chatId int64) ([]*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingNotifications", chatId)
+	ret0, _ := ret[0].([]*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingNotifications indicates an expected call of GetPendingNotifications.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) GetPendingNotifications(chatId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).CreateNotification), notification)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingNotifications", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).GetPendingNotifications), chatId)
 }
 
-// DeleteNotification mocks base method.
-func (m *MockNotificationRepositoryInterface) DeleteNotification(id string) error {
+// ListNotifications mocks base method.
+func (m *MockNotificationRepositoryInterface) ListNotifications(q *models.NotificationQuery) ([]*models.Notification, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteNotification", id)
+	ret := m.ctrl.Call(m, "ListNotifications", q)
+	ret0, _ := ret[0].([]*models.Notification)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) ListNotifications(q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).ListNotifications), q)
+}
+
+// MarkNotificationFailed mocks base method.
+func (m *MockNotificationRepositoryInterface) MarkNotificationFailed(id string, version int, lastError string, occurrence *models.Occurrence) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationFailed", id, version, lastError, occurrence)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteNotification indicates an expected call of DeleteNotification.
-func (mr *MockNotificationRepositoryInterfaceMockRecorder) DeleteNotification(id any) *gomock.Call {
+// MarkNotificationFailed indicates an expected call of MarkNotificationFailed.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) MarkNotificationFailed(id, version, lastError, occurrence any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotification", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).DeleteNotification), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationFailed", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).MarkNotificationFailed), id, version, lastError, occurrence)
 }
 
-// GetAllNotifications mocks base method.
-func (m *MockNotificationRepositoryInterface) GetAllNotifications() ([]*models.Notification, error) {
+// MarkNotificationSending mocks base method.
+func (m *MockNotificationRepositoryInterface) MarkNotificationSending(id string, version int, lease time.Duration) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllNotifications")
-	ret0, _ := ret[0].([]*models.Notification)
+	ret := m.ctrl.Call(m, "MarkNotificationSending", id, version, lease)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllNotifications indicates an expected call of GetAllNotifications.
-func (mr *MockNotificationRepositoryInterfaceMockRecorder) GetAllNotifications() *gomock.Call {
+// MarkNotificationSending indicates an expected call of MarkNotificationSending.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) MarkNotificationSending(id, version, lease any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllNotifications", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).GetAllNotifications))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationSending", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).MarkNotificationSending), id, version, lease)
 }
 
-// GetNotificationStatus mocks base method.
-func (m *MockNotificationRepositoryInterface) GetNotificationStatus(id string) (string, error) {
+// MarkNotificationSent mocks base method.
+func (m *MockNotificationRepositoryInterface) MarkNotificationSent(id string, version int, channel string, chatId int64, messageIds []int, occurrence *models.Occurrence) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNotificationStatus", id)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "MarkNotificationSent", id, version, channel, chatId, messageIds, occurrence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkNotificationSent indicates an expected call of MarkNotificationSent.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) MarkNotificationSent(id, version, channel, chatId, messageIds, occurrence any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationSent", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).MarkNotificationSent), id, version, channel, chatId, messageIds, occurrence)
+}
+
+// ProcessOutbox mocks base method.
+func (m *MockNotificationRepositoryInterface) ProcessOutbox(limit int, publish func(*models.OutboxMessage) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessOutbox", limit, publish)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetNotificationStatus indicates an expected call of GetNotificationStatus.
-func (mr *MockNotificationRepositoryInterfaceMockRecorder) GetNotificationStatus(id any) *gomock.Call {
+// ProcessOutbox indicates an expected call of ProcessOutbox.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) ProcessOutbox(limit, publish any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationStatus", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).GetNotificationStatus), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOutbox", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).ProcessOutbox), limit, publish)
 }
 
-// UpdateNotificationStatus mocks base method.
-func (m *MockNotificationRepositoryInterface) UpdateNotificationStatus(id, status string) error {
+// RescheduleNotification mocks base method.
+func (m *MockNotificationRepositoryInterface) RescheduleNotification(id, sendTime, message string, outbox *models.OutboxMessage) (*models.Notification, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateNotificationStatus", id, status)
+	ret := m.ctrl.Call(m, "RescheduleNotification", id, sendTime, message, outbox)
+	ret0, _ := ret[0].(*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RescheduleNotification indicates an expected call of RescheduleNotification.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) RescheduleNotification(id, sendTime, message, outbox any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleNotification", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).RescheduleNotification), id, sendTime, message, outbox)
+}
+
+// RetractNotification mocks base method.
+func (m *MockNotificationRepositoryInterface) RetractNotification(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetractNotification", id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateNotificationStatus indicates an expected call of UpdateNotificationStatus.
-func (mr *MockNotificationRepositoryInterfaceMockRecorder) UpdateNotificationStatus(id, status any) *gomock.Call {
+// RetractNotification indicates an expected call of RetractNotification.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) RetractNotification(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationStatus", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).UpdateNotificationStatus), id, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetractNotification", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).RetractNotification), id)
+}
+
+// SaveMessageIds mocks base method.
+func (m *MockNotificationRepositoryInterface) SaveMessageIds(id string, version int, messageIds []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMessageIds", id, version, messageIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMessageIds indicates an expected call of SaveMessageIds.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) SaveMessageIds(id, version, messageIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMessageIds", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).SaveMessageIds), id, version, messageIds)
+}
+
+// ScheduleRetry mocks base method.
+func (m *MockNotificationRepositoryInterface) ScheduleRetry(id string, version int, lastError string, outbox *models.OutboxMessage) (*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleRetry", id, version, lastError, outbox)
+	ret0, _ := ret[0].(*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleRetry indicates an expected call of ScheduleRetry.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) ScheduleRetry(id, version, lastError, outbox any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleRetry", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).ScheduleRetry), id, version, lastError, outbox)
+}
+
+// SetChatTimezone mocks base method.
+func (m *MockNotificationRepositoryInterface) SetChatTimezone(chatId int64, timezone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChatTimezone", chatId, timezone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChatTimezone indicates an expected call of SetChatTimezone.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) SetChatTimezone(chatId, timezone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChatTimezone", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).SetChatTimezone), chatId, timezone)
+}
+
+// SnoozeNotification mocks base method.
+func (m *MockNotificationRepositoryInterface) SnoozeNotification(id, sendTime string, outbox *models.OutboxMessage) (*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnoozeNotification", id, sendTime, outbox)
+	ret0, _ := ret[0].(*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnoozeNotification indicates an expected call of SnoozeNotification.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) SnoozeNotification(id, sendTime, outbox any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnoozeNotification", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).SnoozeNotification), id, sendTime, outbox)
+}
+
+// UpdateSentMessage mocks base method.
+func (m *MockNotificationRepositoryInterface) UpdateSentMessage(id, message, format string) (*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSentMessage", id, message, format)
+	ret0, _ := ret[0].(*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSentMessage indicates an expected call of UpdateSentMessage.
+func (mr *MockNotificationRepositoryInterfaceMockRecorder) UpdateSentMessage(id, message, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSentMessage", reflect.TypeOf((*MockNotificationRepositoryInterface)(nil).UpdateSentMessage), id, message, format)
 }
 
 // MockRabbitMQProducerInterface is a mock of RabbitMQProducerInterface interface.
@@ -176,18 +414,258 @@ func (m *MockTelegramClientInterface) EXPECT() *MockTelegramClientInterfaceMockR
 	return m.recorder
 }
 
-// SendMessage mocks base method.
-func (m *MockTelegramClientInterface) SendMessage(chatID int64, text string) error {
+// DeleteMessage mocks base method.
+func (m *MockTelegramClientInterface) DeleteMessage(chatID int64, messageID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendMessage", chatID, text)
+	ret := m.ctrl.Call(m, "DeleteMessage", chatID, messageID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockTelegramClientInterfaceMockRecorder) DeleteMessage(chatID, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockTelegramClientInterface)(nil).DeleteMessage), chatID, messageID)
+}
+
+// EditMessageCaption mocks base method.
+func (m *MockTelegramClientInterface) EditMessageCaption(chatID int64, messageID int, caption, parseMode string, keyboard [][]telegram.Button) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditMessageCaption", chatID, messageID, caption, parseMode, keyboard)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditMessageCaption indicates an expected call of EditMessageCaption.
+func (mr *MockTelegramClientInterfaceMockRecorder) EditMessageCaption(chatID, messageID, caption, parseMode, keyboard any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditMessageCaption", reflect.TypeOf((*MockTelegramClientInterface)(nil).EditMessageCaption), chatID, messageID, caption, parseMode, keyboard)
+}
+
+// EditMessageText mocks base method.
+func (m *MockTelegramClientInterface) EditMessageText(chatID int64, messageID int, text, parseMode string, keyboard [][]telegram.Button) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditMessageText", chatID, messageID, text, parseMode, keyboard)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditMessageText indicates an expected call of EditMessageText.
+func (mr *MockTelegramClientInterfaceMockRecorder) EditMessageText(chatID, messageID, text, parseMode, keyboard any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditMessageText", reflect.TypeOf((*MockTelegramClientInterface)(nil).EditMessageText), chatID, messageID, text, parseMode, keyboard)
+}
+
+// SendAttachment mocks base method.
+func (m *MockTelegramClientInterface) SendAttachment(chatID int64, attachment telegram.Attachment, caption, parseMode string, keyboard [][]telegram.Button) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAttachment", chatID, attachment, caption, parseMode, keyboard)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendAttachment indicates an expected call of SendAttachment.
+func (mr *MockTelegramClientInterfaceMockRecorder) SendAttachment(chatID, attachment, caption, parseMode, keyboard any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAttachment", reflect.TypeOf((*MockTelegramClientInterface)(nil).SendAttachment), chatID, attachment, caption, parseMode, keyboard)
+}
+
+// SendMessage mocks base method.
+func (m *MockTelegramClientInterface) SendMessage(chatID int64, text, parseMode string, keyboard [][]telegram.Button) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", chatID, text, parseMode, keyboard)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // SendMessage indicates an expected call of SendMessage.
-func (mr *MockTelegramClientInterfaceMockRecorder) SendMessage(chatID, text any) *gomock.Call {
+func (mr *MockTelegramClientInterfaceMockRecorder) SendMessage(chatID, text, parseMode, keyboard any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockTelegramClientInterface)(nil).SendMessage), chatID, text, parseMode, keyboard)
+}
+
+// MockTelegramRateLimiterInterface is a mock of TelegramRateLimiterInterface interface.
+type MockTelegramRateLimiterInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockTelegramRateLimiterInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockTelegramRateLimiterInterfaceMockRecorder is the mock recorder for MockTelegramRateLimiterInterface.
+type MockTelegramRateLimiterInterfaceMockRecorder struct {
+	mock *MockTelegramRateLimiterInterface
+}
+
+// NewMockTelegramRateLimiterInterface creates a new mock instance.
+func NewMockTelegramRateLimiterInterface(ctrl *gomock.Controller) *MockTelegramRateLimiterInterface {
+	mock := &MockTelegramRateLimiterInterface{ctrl: ctrl}
+	mock.recorder = &MockTelegramRateLimiterInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTelegramRateLimiterInterface) EXPECT() *MockTelegramRateLimiterInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Wait mocks base method.
+func (m *MockTelegramRateLimiterInterface) Wait(chatID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Wait", chatID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Wait indicates an expected call of Wait.
+func (mr *MockTelegramRateLimiterInterfaceMockRecorder) Wait(chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Wait", reflect.TypeOf((*MockTelegramRateLimiterInterface)(nil).Wait), chatID)
+}
+
+// MockEmailClientInterface is a mock of EmailClientInterface interface.
+type MockEmailClientInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailClientInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockEmailClientInterfaceMockRecorder is the mock recorder for MockEmailClientInterface.
+type MockEmailClientInterfaceMockRecorder struct {
+	mock *MockEmailClientInterface
+}
+
+// NewMockEmailClientInterface creates a new mock instance.
+func NewMockEmailClientInterface(ctrl *gomock.Controller) *MockEmailClientInterface {
+	mock := &MockEmailClientInterface{ctrl: ctrl}
+	mock.recorder = &MockEmailClientInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailClientInterface) EXPECT() *MockEmailClientInterfaceMockRecorder {
+	return m.recorder
+}
+
+// SendEmail mocks base method.
+func (m *MockEmailClientInterface) SendEmail(to, subject, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmail", to, subject, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEmail indicates an expected call of SendEmail.
+func (mr *MockEmailClientInterfaceMockRecorder) SendEmail(to, subject, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmail", reflect.TypeOf((*MockEmailClientInterface)(nil).SendEmail), to, subject, body)
+}
+
+// MockWebhookClientInterface is a mock of WebhookClientInterface interface.
+type MockWebhookClientInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookClientInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookClientInterfaceMockRecorder is the mock recorder for MockWebhookClientInterface.
+type MockWebhookClientInterfaceMockRecorder struct {
+	mock *MockWebhookClientInterface
+}
+
+// NewMockWebhookClientInterface creates a new mock instance.
+func NewMockWebhookClientInterface(ctrl *gomock.Controller) *MockWebhookClientInterface {
+	mock := &MockWebhookClientInterface{ctrl: ctrl}
+	mock.recorder = &MockWebhookClientInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookClientInterface) EXPECT() *MockWebhookClientInterfaceMockRecorder {
+	return m.recorder
+}
+
+// SendWebhook mocks base method.
+func (m *MockWebhookClientInterface) SendWebhook(url string, headers map[string]string, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWebhook", url, headers, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendWebhook indicates an expected call of SendWebhook.
+func (mr *MockWebhookClientInterfaceMockRecorder) SendWebhook(url, headers, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWebhook", reflect.TypeOf((*MockWebhookClientInterface)(nil).SendWebhook), url, headers, payload)
+}
+
+// MockAttachmentStorageInterface is a mock of AttachmentStorageInterface interface.
+type MockAttachmentStorageInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentStorageInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockAttachmentStorageInterfaceMockRecorder is the mock recorder for MockAttachmentStorageInterface.
+type MockAttachmentStorageInterfaceMockRecorder struct {
+	mock *MockAttachmentStorageInterface
+}
+
+// NewMockAttachmentStorageInterface creates a new mock instance.
+func NewMockAttachmentStorageInterface(ctrl *gomock.Controller) *MockAttachmentStorageInterface {
+	mock := &MockAttachmentStorageInterface{ctrl: ctrl}
+	mock.recorder = &MockAttachmentStorageInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentStorageInterface) EXPECT() *MockAttachmentStorageInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockAttachmentStorageInterface) Delete(key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAttachmentStorageInterfaceMockRecorder) Delete(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAttachmentStorageInterface)(nil).Delete), key)
+}
+
+// Open mocks base method.
+func (m *MockAttachmentStorageInterface) Open(key string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", key)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockAttachmentStorageInterfaceMockRecorder) Open(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockAttachmentStorageInterface)(nil).Open), key)
+}
+
+// Save mocks base method.
+func (m *MockAttachmentStorageInterface) Save(name string, r io.Reader) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", name, r)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockAttachmentStorageInterfaceMockRecorder) Save(name, r any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockTelegramClientInterface)(nil).SendMessage), chatID, text)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockAttachmentStorageInterface)(nil).Save), name, r)
 }
 
 // MockRedisClientInterface is a mock of RedisClientInterface interface.
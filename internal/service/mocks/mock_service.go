@@ -11,8 +11,10 @@ package mocks
 
 import (
 	models "DelayedNotifier/internal/models"
+	io "io"
 	reflect "reflect"
 
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -55,6 +57,21 @@ func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) CreateNotification(ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).CreateNotification), arg0)
 }
 
+// CreateNotificationWithAttachment mocks base method.
+func (m *MockServiceDelayedNotifierInterface) CreateNotificationWithAttachment(nf *models.Notification, fileName string, file io.Reader) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotificationWithAttachment", nf, fileName, file)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotificationWithAttachment indicates an expected call of CreateNotificationWithAttachment.
+func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) CreateNotificationWithAttachment(nf, fileName, file any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotificationWithAttachment", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).CreateNotificationWithAttachment), nf, fileName, file)
+}
+
 // DeleteNotification mocks base method.
 func (m *MockServiceDelayedNotifierInterface) DeleteNotification(id string) error {
 	m.ctrl.T.Helper()
@@ -69,34 +86,64 @@ func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) DeleteNotification(id
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotification", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).DeleteNotification), id)
 }
 
-// GetAllNotifications mocks base method.
-func (m *MockServiceDelayedNotifierInterface) GetAllNotifications() ([]*models.Notification, error) {
+// EditSentMessage mocks base method.
+func (m *MockServiceDelayedNotifierInterface) EditSentMessage(id string, req *models.Notification) (*models.Notification, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllNotifications")
-	ret0, _ := ret[0].([]*models.Notification)
+	ret := m.ctrl.Call(m, "EditSentMessage", id, req)
+	ret0, _ := ret[0].(*models.Notification)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllNotifications indicates an expected call of GetAllNotifications.
-func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) GetAllNotifications() *gomock.Call {
+// EditSentMessage indicates an expected call of EditSentMessage.
+func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) EditSentMessage(id, req any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllNotifications", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).GetAllNotifications))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditSentMessage", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).EditSentMessage), id, req)
 }
 
-// GetNotificationStatus mocks base method.
-func (m *MockServiceDelayedNotifierInterface) GetNotificationStatus(id string) (string, error) {
+// GetNotification mocks base method.
+func (m *MockServiceDelayedNotifierInterface) GetNotification(id string) (*models.Notification, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNotificationStatus", id)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetNotification", id)
+	ret0, _ := ret[0].(*models.Notification)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetNotificationStatus indicates an expected call of GetNotificationStatus.
-func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) GetNotificationStatus(id any) *gomock.Call {
+// GetNotification indicates an expected call of GetNotification.
+func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) GetNotification(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationStatus", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).GetNotificationStatus), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotification", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).GetNotification), id)
+}
+
+// GetNotificationEvents mocks base method.
+func (m *MockServiceDelayedNotifierInterface) GetNotificationEvents(id string) ([]*models.NotificationEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationEvents", id)
+	ret0, _ := ret[0].([]*models.NotificationEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationEvents indicates an expected call of GetNotificationEvents.
+func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) GetNotificationEvents(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationEvents", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).GetNotificationEvents), id)
+}
+
+// ListNotifications mocks base method.
+func (m *MockServiceDelayedNotifierInterface) ListNotifications(filter *models.NotificationFilter) (*models.NotificationPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", filter)
+	ret0, _ := ret[0].(*models.NotificationPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) ListNotifications(filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).ListNotifications), filter)
 }
 
 // ProcessNotification mocks base method.
@@ -112,3 +159,68 @@ func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) ProcessNotification(n
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessNotification", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).ProcessNotification), nf)
 }
+
+// RescheduleNotification mocks base method.
+func (m *MockServiceDelayedNotifierInterface) RescheduleNotification(id string, req *models.Notification) (*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleNotification", id, req)
+	ret0, _ := ret[0].(*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RescheduleNotification indicates an expected call of RescheduleNotification.
+func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) RescheduleNotification(id, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleNotification", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).RescheduleNotification), id, req)
+}
+
+// RetractNotification mocks base method.
+func (m *MockServiceDelayedNotifierInterface) RetractNotification(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetractNotification", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetractNotification indicates an expected call of RetractNotification.
+func (mr *MockServiceDelayedNotifierInterfaceMockRecorder) RetractNotification(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetractNotification", reflect.TypeOf((*MockServiceDelayedNotifierInterface)(nil).RetractNotification), id)
+}
+
+// MockTelegramUpdateHandler is a mock of TelegramUpdateHandler interface.
+type MockTelegramUpdateHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockTelegramUpdateHandlerMockRecorder
+	isgomock struct{}
+}
+
+// MockTelegramUpdateHandlerMockRecorder is the mock recorder for MockTelegramUpdateHandler.
+type MockTelegramUpdateHandlerMockRecorder struct {
+	mock *MockTelegramUpdateHandler
+}
+
+// NewMockTelegramUpdateHandler creates a new mock instance.
+func NewMockTelegramUpdateHandler(ctrl *gomock.Controller) *MockTelegramUpdateHandler {
+	mock := &MockTelegramUpdateHandler{ctrl: ctrl}
+	mock.recorder = &MockTelegramUpdateHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTelegramUpdateHandler) EXPECT() *MockTelegramUpdateHandlerMockRecorder {
+	return m.recorder
+}
+
+// HandleUpdate mocks base method.
+func (m *MockTelegramUpdateHandler) HandleUpdate(update tgbotapi.Update) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleUpdate", update)
+}
+
+// HandleUpdate indicates an expected call of HandleUpdate.
+func (mr *MockTelegramUpdateHandlerMockRecorder) HandleUpdate(update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleUpdate", reflect.TypeOf((*MockTelegramUpdateHandler)(nil).HandleUpdate), update)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/pkg/logger"
+	"context"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxBatchSize    = 100
+)
+
+type OutboxRelay struct {
+	repo      NotificationRepositoryInterface
+	producer  RabbitMQProducerInterface
+	interval  time.Duration
+	batchSize int
+}
+
+func NewOutboxRelay(repo NotificationRepositoryInterface, producer RabbitMQProducerInterface, cfg *config.Config) *OutboxRelay {
+	interval := time.Duration(cfg.GetInt("OUTBOX_POLL_INTERVAL_MS")) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultOutboxPollInterval
+	}
+	batchSize := cfg.GetInt("OUTBOX_BATCH_SIZE")
+	if batchSize <= 0 {
+		batchSize = defaultOutboxBatchSize
+	}
+
+	return &OutboxRelay{
+		repo:      repo,
+		producer:  producer,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+func (r *OutboxRelay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Drain the backlog before waiting for the next tick.
+			for {
+				processed, err := r.RelayBatch(ctx)
+				if err != nil || processed < r.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	processed, err := r.repo.ProcessOutbox(r.batchSize, func(msg *models.OutboxMessage) error {
+		delay := time.Until(msg.SendAt)
+		if delay < 0 {
+			delay = 0
+		}
+		return r.producer.Publish(msg.Payload, ctx, msg.RoutingKey, delay)
+	})
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error("Outbox relay failed",
+			zap.Error(err))
+		return 0, err
+	}
+
+	if processed > 0 {
+		logger.GetLoggerFromCtx(ctx).Info("Outbox messages relayed",
+			zap.Int("count", processed))
+	}
+	return processed, nil
+}
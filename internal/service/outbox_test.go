@@ -0,0 +1,65 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/repository/mocks"
+	servicemocks "DelayedNotifier/internal/service/mocks"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOutboxRelay_RelayBatchPublishesWithDelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	producer := servicemocks.NewMockRabbitMQProducerInterface(ctrl)
+
+	messages := []*models.OutboxMessage{
+		{Id: 1, NotificationId: "id-1", Payload: []byte(`{"id":"id-1"}`), RoutingKey: "test.routing.key", SendAt: time.Now().Add(-time.Minute)},
+		{Id: 2, NotificationId: "id-2", Payload: []byte(`{"id":"id-2"}`), RoutingKey: "test.routing.key", SendAt: time.Now().Add(time.Hour)},
+	}
+
+	repo.EXPECT().ProcessOutbox(10, gomock.Any()).DoAndReturn(
+		func(limit int, publish func(*models.OutboxMessage) error) (int, error) {
+			for _, msg := range messages {
+				if err := publish(msg); err != nil {
+					return 0, err
+				}
+			}
+			return len(messages), nil
+		}).Times(1)
+	producer.EXPECT().Publish([]byte(`{"id":"id-1"}`), gomock.Any(), "test.routing.key", time.Duration(0)).Return(nil).Times(1)
+	producer.EXPECT().Publish([]byte(`{"id":"id-2"}`), gomock.Any(), "test.routing.key", gomock.Any()).DoAndReturn(
+		func(data []byte, _ any, _ string, delay time.Duration) error {
+			require.Greater(t, delay, 59*time.Minute)
+			return nil
+		}).Times(1)
+
+	relay := &OutboxRelay{repo: repo, producer: producer, interval: time.Second, batchSize: 10}
+
+	processed, err := relay.RelayBatch(setupTestContext())
+	require.NoError(t, err)
+	require.Equal(t, 2, processed)
+}
+
+func TestOutboxRelay_RelayBatchRepoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	producer := servicemocks.NewMockRabbitMQProducerInterface(ctrl)
+
+	expectedErr := errors.New("database error")
+	repo.EXPECT().ProcessOutbox(10, gomock.Any()).Return(0, expectedErr).Times(1)
+
+	relay := &OutboxRelay{repo: repo, producer: producer, interval: time.Second, batchSize: 10}
+
+	processed, err := relay.RelayBatch(setupTestContext())
+	require.Error(t, err)
+	require.Zero(t, processed)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+)
+
+const (
+	defaultRetryMaxAttempts = 5
+	defaultRetryBackoff     = 30 * time.Second
+	defaultRetryMaxAge      = 24 * time.Hour
+	maxRetryDelay           = time.Hour
+)
+
+// RetryPolicy decides whether a transient delivery failure is retried and
+// when. Delays grow exponentially from Backoff; MaxAge bounds how late after
+// the scheduled time a notification may still be delivered.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxAge      time.Duration
+}
+
+func newRetryPolicy(cfg *config.Config) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts: cfg.GetInt("RETRY_MAX_ATTEMPTS"),
+		Backoff:     time.Duration(cfg.GetInt("RETRY_BACKOFF_MS")) * time.Millisecond,
+		MaxAge:      time.Duration(cfg.GetInt("RETRY_MAX_AGE_MS")) * time.Millisecond,
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = defaultRetryBackoff
+	}
+	if policy.MaxAge <= 0 {
+		policy.MaxAge = defaultRetryMaxAge
+	}
+	return policy
+}
+
+func validateRetryPolicy(nf *models.Notification) error {
+	if nf.MaxAttempts < 0 {
+		return errors.New("max_attempts must not be negative")
+	}
+	if nf.Backoff != "" {
+		if d, err := time.ParseDuration(nf.Backoff); err != nil || d <= 0 {
+			return fmt.Errorf("invalid backoff %q (use a positive duration such as 30s)", nf.Backoff)
+		}
+	}
+	if nf.MaxAge != "" {
+		if d, err := time.ParseDuration(nf.MaxAge); err != nil || d <= 0 {
+			return fmt.Errorf("invalid max_age %q (use a positive duration such as 2h)", nf.MaxAge)
+		}
+	}
+	return nil
+}
+
+// forNotification overrides the defaults with the values set on nf. Invalid
+// values are rejected at creation, so they are ignored here.
+func (p RetryPolicy) forNotification(nf *models.Notification) RetryPolicy {
+	if nf.MaxAttempts > 0 {
+		p.MaxAttempts = nf.MaxAttempts
+	}
+	if d, err := time.ParseDuration(nf.Backoff); err == nil && d > 0 {
+		p.Backoff = d
+	}
+	if d, err := time.ParseDuration(nf.MaxAge); err == nil && d > 0 {
+		p.MaxAge = d
+	}
+	return p
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// next returns when to retry after the given failed attempt, or false when
+// the policy is exhausted. minDelay is a wait requested by the channel itself,
+// such as Telegram's retry_after.
+func (p RetryPolicy) next(nf *models.Notification, attempt int, now time.Time, minDelay time.Duration) (time.Time, bool) {
+	if attempt >= p.MaxAttempts {
+		return time.Time{}, false
+	}
+
+	retryAt := now.Add(max(p.delay(attempt), minDelay))
+	if p.MaxAge > 0 {
+		scheduled, err := time.Parse(time.RFC3339, nf.Time)
+		if err != nil {
+			scheduled = now
+		}
+		if retryAt.After(scheduled.Add(p.MaxAge)) {
+			return time.Time{}, false
+		}
+	}
+	return retryAt, true
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRetryPolicy(t *testing.T) {
+	require.NoError(t, validateRetryPolicy(&models.Notification{}))
+	require.NoError(t, validateRetryPolicy(&models.Notification{MaxAttempts: 3, Backoff: "10s", MaxAge: "2h"}))
+	require.Error(t, validateRetryPolicy(&models.Notification{MaxAttempts: -1}))
+	require.Error(t, validateRetryPolicy(&models.Notification{Backoff: "soon"}))
+	require.Error(t, validateRetryPolicy(&models.Notification{MaxAge: "-1h"}))
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: 10 * time.Second}
+
+	require.Equal(t, 10*time.Second, policy.delay(1))
+	require.Equal(t, 20*time.Second, policy.delay(2))
+	require.Equal(t, 40*time.Second, policy.delay(3))
+	require.Equal(t, maxRetryDelay, policy.delay(30))
+}
+
+func TestRetryPolicyNext(t *testing.T) {
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	defaults := RetryPolicy{MaxAttempts: 5, Backoff: 30 * time.Second, MaxAge: 24 * time.Hour}
+	nf := &models.Notification{Time: "2026-02-13T09:00:00Z", MaxAttempts: 3, Backoff: "1m", MaxAge: "10m"}
+
+	policy := defaults.forNotification(nf)
+	require.Equal(t, RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxAge: 10 * time.Minute}, policy)
+
+	retryAt, ok := policy.next(nf, 1, now, 0)
+	require.True(t, ok)
+	require.Equal(t, now.Add(time.Minute), retryAt)
+
+	retryAt, ok = policy.next(nf, 2, now, 0)
+	require.True(t, ok)
+	require.Equal(t, now.Add(2*time.Minute), retryAt)
+
+	retryAt, ok = policy.next(nf, 1, now, 5*time.Minute)
+	require.True(t, ok)
+	require.Equal(t, now.Add(5*time.Minute), retryAt, "retry_after wins over a shorter backoff")
+
+	_, ok = policy.next(nf, 3, now, 0)
+	require.False(t, ok, "max_attempts exhausted")
+
+	_, ok = policy.next(nf, 1, now.Add(9*time.Minute+30*time.Second), 0)
+	require.False(t, ok, "retry would land after max_age")
+
+	require.Equal(t, defaults, defaults.forNotification(&models.Notification{}))
+}
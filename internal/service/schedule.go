@@ -0,0 +1,81 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const intervalPrefix = "every "
+
+// parseSchedule accepts standard five-field cron expressions, descriptors such
+// as "@daily" and simple intervals written as "every 1h" or "@every 1h".
+func parseSchedule(expr string) (cron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, intervalPrefix) {
+		expr = "@" + expr
+	}
+
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", expr, err)
+	}
+	return schedule, nil
+}
+
+func validateRecurrence(nf *models.Notification) error {
+	if nf.Schedule == "" {
+		if nf.EndAt != "" || nf.MaxOccurrences != 0 {
+			return errors.New("end_at and max_occurrences require a schedule")
+		}
+		return nil
+	}
+
+	if _, err := parseSchedule(nf.Schedule); err != nil {
+		return err
+	}
+	if nf.EndAt != "" {
+		if _, err := time.Parse(time.RFC3339, nf.EndAt); err != nil {
+			return fmt.Errorf("invalid end_at format (use RFC3339): %w", err)
+		}
+	}
+	if nf.MaxOccurrences < 0 {
+		return errors.New("max_occurrences must not be negative")
+	}
+	return nil
+}
+
+// nextOccurrence returns the firing time that follows the one described by
+// nf.Time, or false when the series is exhausted by end_at or max_occurrences.
+func nextOccurrence(nf *models.Notification, fired int, now time.Time) (time.Time, bool, error) {
+	if nf.MaxOccurrences > 0 && fired >= nf.MaxOccurrences {
+		return time.Time{}, false, nil
+	}
+
+	schedule, err := parseSchedule(nf.Schedule)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	after := now
+	if last, err := time.Parse(time.RFC3339, nf.Time); err == nil && last.After(now) {
+		after = last
+	}
+	next := schedule.Next(after)
+
+	if nf.EndAt != "" {
+		endAt, err := time.Parse(time.RFC3339, nf.EndAt)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid end_at format (use RFC3339): %w", err)
+		}
+		if next.After(endAt) {
+			return time.Time{}, false, nil
+		}
+	}
+
+	return next, true, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/config"
+)
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2026, 2, 13, 9, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		expr   string
+		next   time.Time
+		expErr bool
+	}{
+		{
+			name: "cron expression",
+			expr: "0 10 * * 1-5",
+			next: time.Date(2026, 2, 13, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "simple interval",
+			expr: "every 1h",
+			next: now.Add(time.Hour),
+		},
+		{
+			name: "descriptor interval",
+			expr: "@every 15m",
+			next: now.Add(15 * time.Minute),
+		},
+		{
+			name:   "invalid expression",
+			expr:   "every banana",
+			expErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := parseSchedule(tc.expr)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.next, schedule.Next(now).UTC())
+		})
+	}
+}
+
+func TestValidateRecurrence(t *testing.T) {
+	require.NoError(t, validateRecurrence(&models.Notification{}))
+	require.NoError(t, validateRecurrence(&models.Notification{Schedule: "every 1h", EndAt: "2026-03-01T00:00:00Z", MaxOccurrences: 3}))
+	require.Error(t, validateRecurrence(&models.Notification{MaxOccurrences: 3}))
+	require.Error(t, validateRecurrence(&models.Notification{Schedule: "every 1h", EndAt: "tomorrow"}))
+	require.Error(t, validateRecurrence(&models.Notification{Schedule: "every 1h", MaxOccurrences: -1}))
+}
+
+func TestNextOccurrence(t *testing.T) {
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+
+	nf := &models.Notification{Schedule: "every 1h", Time: "2026-02-13T09:00:00Z"}
+	next, ok, err := nextOccurrence(nf, 1, now)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, now.Add(time.Hour), next.UTC())
+
+	nf.MaxOccurrences = 2
+	_, ok, err = nextOccurrence(nf, 2, now)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	nf.MaxOccurrences = 0
+	nf.EndAt = "2026-02-13T09:30:00Z"
+	_, ok, err = nextOccurrence(nf, 1, now)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestDelayedNotifierService_FinishOccurrence(t *testing.T) {
+	srv := &DelayedNotifierService{ctx: setupTestContext(), cfg: config.New()}
+
+	require.Nil(t, srv.finishOccurrence(&models.Notification{Id: "one-off"}))
+
+	nf := &models.Notification{Id: "recurring", Schedule: "every 1h", Time: time.Now().Format(time.RFC3339), MaxOccurrences: 2}
+	occurrence := srv.finishOccurrence(nf)
+	require.NotNil(t, occurrence)
+	require.NotEmpty(t, occurrence.NextTime)
+	require.WithinDuration(t, time.Now().Add(time.Hour), occurrence.Outbox.SendAt, time.Minute)
+
+	nf.Occurrences = 1
+	occurrence = srv.finishOccurrence(nf)
+	require.NotNil(t, occurrence)
+	require.Empty(t, occurrence.NextTime)
+	require.Nil(t, occurrence.Outbox)
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/telegram"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	telegramMaxMessageLength = 4096
+	telegramMaxCaptionLength = 1024
+)
+
+// Capabilities describes what a channel is able to deliver. MaxLength is in
+// characters, zero means unlimited. Splitting means a message longer than
+// MaxLength can be sent as several parts when the notification asks for it.
+type Capabilities struct {
+	Formatting  bool
+	Attachments bool
+	Buttons     bool
+	MaxLength   int
+	Splitting   bool
+}
+
+// Sender delivers notifications over a single channel. Adapters for new
+// channels only need to implement it and be registered in a SenderRegistry.
+// Send returns the channel's ids of the delivered messages when it has any,
+// also on failure for the parts that did go out.
+type Sender interface {
+	Channel() string
+	Capabilities() Capabilities
+	Validate(nf *models.Notification) error
+	Send(nf *models.Notification) ([]int, error)
+}
+
+// MessageEditor is implemented by senders whose delivered messages can still
+// be changed afterwards. Edit replaces the text with nf.Message and Retract
+// deletes the messages; both act on the messages listed in nf.MessageIds.
+type MessageEditor interface {
+	Edit(nf *models.Notification) error
+	Retract(nf *models.Notification) error
+}
+
+// PermanentError marks a delivery failure that retrying the same channel
+// cannot fix, e.g. a rejected recipient. Such failures move a notification to
+// the next channel of its fallback chain.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err was marked permanent, either explicitly or
+// by a client error that knows it is not worth retrying.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return true
+	}
+	var retryable interface{ Retryable() bool }
+	if errors.As(err, &retryable) {
+		return !retryable.Retryable()
+	}
+	return false
+}
+
+// RetryAfter returns the minimum delay a channel asked to wait before the
+// next attempt, or zero.
+func RetryAfter(err error) time.Duration {
+	var hint interface{ RetryDelay() time.Duration }
+	if errors.As(err, &hint) {
+		return hint.RetryDelay()
+	}
+	return 0
+}
+
+type SenderRegistry struct {
+	senders map[string]Sender
+}
+
+func NewSenderRegistry(senders ...Sender) *SenderRegistry {
+	registry := &SenderRegistry{senders: make(map[string]Sender, len(senders))}
+	for _, sender := range senders {
+		registry.Register(sender)
+	}
+	return registry
+}
+
+// Register adds a sender, replacing any sender previously registered for the
+// same channel.
+func (r *SenderRegistry) Register(sender Sender) {
+	r.senders[sender.Channel()] = sender
+}
+
+func (r *SenderRegistry) Get(channel string) (Sender, error) {
+	sender, ok := r.senders[channel]
+	if !ok {
+		return nil, fmt.Errorf("unsupported channel: %s", channel)
+	}
+	return sender, nil
+}
+
+type TelegramSender struct {
+	client  TelegramClientInterface
+	limiter TelegramRateLimiterInterface
+	storage AttachmentStorageInterface
+}
+
+// NewTelegramSender creates a Telegram sender. limiter may be nil to send
+// without flood control, storage may be nil when attachments are only sent by
+// URL.
+func NewTelegramSender(client TelegramClientInterface, limiter TelegramRateLimiterInterface, storage AttachmentStorageInterface) *TelegramSender {
+	return &TelegramSender{client: client, limiter: limiter, storage: storage}
+}
+
+func (s *TelegramSender) Channel() string {
+	return models.ChannelTelegram
+}
+
+func (s *TelegramSender) Capabilities() Capabilities {
+	return Capabilities{Formatting: true, Attachments: true, Buttons: true, MaxLength: telegramMaxMessageLength, Splitting: true}
+}
+
+// Validate parses formatted messages the way Telegram will, part by part when
+// the message is split, so broken markup is rejected at creation instead of
+// failing with a 400 at send time.
+func (s *TelegramSender) Validate(nf *models.Notification) error {
+	if nf.ChatId == 0 {
+		return errors.New("chat_id is required for telegram channel")
+	}
+	if nf.Attachment != nil {
+		if err := validateAttachment(nf.Attachment); err != nil {
+			return fmt.Errorf("invalid attachment: %w", err)
+		}
+	}
+
+	parts := telegramParts(nf)
+	for i, part := range parts {
+		var err error
+		switch nf.Format {
+		case models.FormatMarkdownV2:
+			err = telegram.ValidateMarkdownV2(part)
+		case models.FormatHTML:
+			err = telegram.ValidateHTML(part)
+		}
+		if err != nil && len(parts) > 1 {
+			return fmt.Errorf("invalid %s message in part %d: %w", nf.Format, i+1, err)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s message: %w", nf.Format, err)
+		}
+	}
+	return nil
+}
+
+// Send delivers the attachment first, with the text as its caption when it
+// fits, and then the text parts. Every message sent counts as a step, so a
+// retry resumes after the steps recorded in nf.MessageIds.
+func (s *TelegramSender) Send(nf *models.Notification) ([]int, error) {
+	parts := telegramParts(nf)
+	parseMode := telegramParseMode(nf.Format)
+
+	caption := ""
+	if nf.Attachment != nil && nf.Attachment.Type != models.AttachmentLocation &&
+		len(parts) == 1 && utf8.RuneCountInString(parts[0]) <= telegramMaxCaptionLength {
+		caption, parts = parts[0], nil
+	}
+
+	steps := len(parts)
+	first := 0
+	if nf.Attachment != nil {
+		steps++
+		first = 1
+	}
+
+	ids := append([]int(nil), nf.MessageIds...)
+	for i := min(len(ids), steps); i < steps; i++ {
+		if s.limiter != nil {
+			if err := s.limiter.Wait(nf.ChatId); err != nil {
+				return ids, err
+			}
+		}
+		// Buttons go under the last message only.
+		var keyboard [][]telegram.Button
+		if i == steps-1 {
+			keyboard = telegramKeyboard(nf)
+		}
+
+		var id int
+		var err error
+		if i < first {
+			id, err = s.sendAttachment(nf, caption, parseMode, keyboard)
+		} else {
+			id, err = s.client.SendMessage(nf.ChatId, parts[i-first], parseMode, keyboard)
+		}
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Edit changes the caption when the text was sent as one, and otherwise the
+// single text message; split messages cannot be edited. The keyboard is sent
+// again unless a button press has already removed it.
+func (s *TelegramSender) Edit(nf *models.Notification) error {
+	parseMode := telegramParseMode(nf.Format)
+	var keyboard [][]telegram.Button
+	if nf.AcknowledgedAt == "" {
+		keyboard = telegramKeyboard(nf)
+	}
+
+	ids := nf.MessageIds
+	if nf.Attachment != nil && nf.Attachment.Type != models.AttachmentLocation && len(ids) == 1 {
+		if utf8.RuneCountInString(nf.Message) > telegramMaxCaptionLength {
+			return fmt.Errorf("a caption cannot be longer than %d characters", telegramMaxCaptionLength)
+		}
+		return s.client.EditMessageCaption(nf.ChatId, ids[0], nf.Message, parseMode, keyboard)
+	}
+
+	first := 0
+	if nf.Attachment != nil {
+		first = 1
+	}
+	if len(ids)-first != 1 {
+		return errors.New("only a message sent as a single part can be edited")
+	}
+	return s.client.EditMessageText(nf.ChatId, ids[first], nf.Message, parseMode, keyboard)
+}
+
+func (s *TelegramSender) Retract(nf *models.Notification) error {
+	for _, id := range nf.MessageIds {
+		if err := s.client.DeleteMessage(nf.ChatId, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TelegramSender) sendAttachment(nf *models.Notification, caption, parseMode string, keyboard [][]telegram.Button) (int, error) {
+	attachment := telegram.Attachment{
+		Type:      nf.Attachment.Type,
+		URL:       nf.Attachment.URL,
+		FileName:  nf.Attachment.FileName,
+		Latitude:  nf.Attachment.Latitude,
+		Longitude: nf.Attachment.Longitude,
+	}
+	if nf.Attachment.FileKey != "" {
+		if s.storage == nil {
+			return 0, Permanent(errors.New("attachment storage is not configured"))
+		}
+		file, err := s.storage.Open(nf.Attachment.FileKey)
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, Permanent(fmt.Errorf("attachment file is missing: %w", err))
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to open attachment: %w", err)
+		}
+		defer file.Close()
+		attachment.Reader = file
+	}
+	return s.client.SendAttachment(nf.ChatId, attachment, caption, parseMode, keyboard)
+}
+
+func telegramParts(nf *models.Notification) []string {
+	if nf.Attachment != nil && strings.TrimSpace(nf.Message) == "" {
+		return nil
+	}
+	if nf.Split {
+		return splitMessage(nf.Message, telegramMaxMessageLength)
+	}
+	return []string{nf.Message}
+}
+
+func telegramParseMode(format string) string {
+	switch format {
+	case models.FormatMarkdownV2:
+		return telegram.ParseModeMarkdownV2
+	case models.FormatHTML:
+		return telegram.ParseModeHTML
+	}
+	return ""
+}
+
+type EmailSender struct {
+	client EmailClientInterface
+}
+
+func NewEmailSender(client EmailClientInterface) *EmailSender {
+	return &EmailSender{client: client}
+}
+
+func (s *EmailSender) Channel() string {
+	return models.ChannelEmail
+}
+
+func (s *EmailSender) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+func (s *EmailSender) Validate(nf *models.Notification) error {
+	if nf.Recipient == "" {
+		return errors.New("recipient is required for email channel")
+	}
+	if _, err := mail.ParseAddress(nf.Recipient); err != nil {
+		return fmt.Errorf("invalid email recipient: %w", err)
+	}
+	return nil
+}
+
+func (s *EmailSender) Send(nf *models.Notification) ([]int, error) {
+	// Validate accepts display names such as "Name <user@example.com>", but
+	// only the bare address may go into the envelope and headers.
+	addr, err := mail.ParseAddress(nf.Recipient)
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("invalid email recipient: %w", err))
+	}
+	err = s.client.SendEmail(addr.Address, nf.Subject, nf.Message)
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+		return nil, Permanent(err)
+	}
+	return nil, err
+}
+
+type WebhookSender struct {
+	client WebhookClientInterface
+}
+
+func NewWebhookSender(client WebhookClientInterface) *WebhookSender {
+	return &WebhookSender{client: client}
+}
+
+func (s *WebhookSender) Channel() string {
+	return models.ChannelWebhook
+}
+
+func (s *WebhookSender) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+func (s *WebhookSender) Validate(nf *models.Notification) error {
+	if nf.Recipient == "" {
+		return errors.New("recipient is required for webhook channel")
+	}
+	u, err := url.ParseRequestURI(nf.Recipient)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url: %s", nf.Recipient)
+	}
+	return nil
+}
+
+func (s *WebhookSender) Send(nf *models.Notification) ([]int, error) {
+	payload, err := webhookPayload(nf)
+	if err != nil {
+		return nil, err
+	}
+	return nil, s.client.SendWebhook(nf.Recipient, nf.Headers, payload)
+}
+
+// webhookPayload returns the caller-supplied JSON payload as is, or a default
+// body describing the notification when none was given.
+func webhookPayload(nf *models.Notification) ([]byte, error) {
+	if len(nf.Payload) > 0 {
+		return nf.Payload, nil
+	}
+	payload, err := json.Marshal(map[string]any{
+		"id":      nf.Id,
+		"message": nf.Message,
+		"time":    nf.Time,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/repository/mocks"
+	servicemocks "DelayedNotifier/internal/service/mocks"
+	"DelayedNotifier/internal/webhook"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeSender struct {
+	channel string
+	sent    []*models.Notification
+}
+
+func (s *fakeSender) Channel() string {
+	return s.channel
+}
+
+func (s *fakeSender) Capabilities() Capabilities {
+	return Capabilities{Formatting: true, MaxLength: 40000}
+}
+
+func (s *fakeSender) Validate(nf *models.Notification) error {
+	if nf.Recipient == "" {
+		return errors.New("recipient is required for " + s.channel + " channel")
+	}
+	return nil
+}
+
+func (s *fakeSender) Send(nf *models.Notification) ([]int, error) {
+	s.sent = append(s.sent, nf)
+	return nil, nil
+}
+
+func TestSenderRegistry(t *testing.T) {
+	telegramSender := NewTelegramSender(nil, nil, nil)
+	registry := NewSenderRegistry(telegramSender, NewEmailSender(nil))
+
+	sender, err := registry.Get(models.ChannelTelegram)
+	require.NoError(t, err)
+	require.Same(t, telegramSender, sender)
+	require.Equal(t, 4096, sender.Capabilities().MaxLength)
+
+	_, err = registry.Get("slack")
+	require.EqualError(t, err, "unsupported channel: slack")
+
+	slack := &fakeSender{channel: "slack"}
+	registry.Register(slack)
+	sender, err = registry.Get("slack")
+	require.NoError(t, err)
+	require.Same(t, slack, sender)
+}
+
+func TestSenderValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		sender Sender
+		nf     *models.Notification
+		expErr string
+	}{
+		{
+			name:   "telegram ok",
+			sender: NewTelegramSender(nil, nil, nil),
+			nf:     &models.Notification{ChatId: 1},
+		},
+		{
+			name:   "telegram without chat_id",
+			sender: NewTelegramSender(nil, nil, nil),
+			nf:     &models.Notification{},
+			expErr: "chat_id is required",
+		},
+		{
+			name:   "telegram markdown",
+			sender: NewTelegramSender(nil, nil, nil),
+			nf:     &models.Notification{ChatId: 1, Format: models.FormatMarkdownV2, Message: "*Standup* at 10:30"},
+		},
+		{
+			name:   "telegram broken html",
+			sender: NewTelegramSender(nil, nil, nil),
+			nf:     &models.Notification{ChatId: 1, Format: models.FormatHTML, Message: "<b>Standup"},
+			expErr: "invalid html message: unclosed tag <b>",
+		},
+		{
+			name:   "telegram entity split across parts",
+			sender: NewTelegramSender(nil, nil, nil),
+			nf:     &models.Notification{ChatId: 1, Format: models.FormatHTML, Split: true, Message: "<b>" + strings.Repeat("a ", 2100) + "</b>"},
+			expErr: "invalid html message in part 1",
+		},
+		{
+			name:   "email ok",
+			sender: NewEmailSender(nil),
+			nf:     &models.Notification{Recipient: "user@example.com"},
+		},
+		{
+			name:   "email invalid address",
+			sender: NewEmailSender(nil),
+			nf:     &models.Notification{Recipient: "user"},
+			expErr: "invalid email recipient",
+		},
+		{
+			name:   "webhook ok",
+			sender: NewWebhookSender(nil),
+			nf:     &models.Notification{Recipient: "http://localhost:8080/hook"},
+		},
+		{
+			name:   "webhook relative url",
+			sender: NewWebhookSender(nil),
+			nf:     &models.Notification{Recipient: "/hook"},
+			expErr: "invalid webhook url",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.sender.Validate(tc.nf)
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expErr)
+		})
+	}
+}
+
+func TestTelegramSenderRateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := servicemocks.NewMockTelegramClientInterface(ctrl)
+	limiter := servicemocks.NewMockTelegramRateLimiterInterface(ctrl)
+	sender := NewTelegramSender(client, limiter, nil)
+	nf := &models.Notification{ChatId: 42, Message: "Test message"}
+
+	gomock.InOrder(
+		limiter.EXPECT().Wait(int64(42)).Return(nil),
+		client.EXPECT().SendMessage(int64(42), "Test message", "", gomock.Nil()).Return(1, nil),
+	)
+	ids, err := sender.Send(nf)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, ids)
+
+	limiter.EXPECT().Wait(int64(42)).Return(rateLimitedError{})
+	client.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	_, err = sender.Send(nf)
+	require.Error(t, err)
+	require.False(t, IsPermanent(err))
+	require.Equal(t, 17*time.Second, RetryAfter(err))
+}
+
+func TestTelegramSenderSplitsAndResumes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := servicemocks.NewMockTelegramClientInterface(ctrl)
+	sender := NewTelegramSender(client, nil, nil)
+
+	first := strings.Repeat("a", 4000)
+	second := strings.Repeat("b", 4000)
+	third := strings.Repeat("c", 200)
+	nf := &models.Notification{ChatId: 42, Message: first + "\n\n" + second + "\n" + third, Split: true}
+
+	gomock.InOrder(
+		client.EXPECT().SendMessage(int64(42), first, "", gomock.Nil()).Return(10, nil),
+		client.EXPECT().SendMessage(int64(42), second, "", gomock.Nil()).Return(0, errors.New("timeout")),
+	)
+	ids, err := sender.Send(nf)
+	require.Error(t, err)
+	require.Equal(t, []int{10}, ids)
+
+	nf.MessageIds = ids
+	gomock.InOrder(
+		client.EXPECT().SendMessage(int64(42), second, "", gomock.Nil()).Return(11, nil),
+		client.EXPECT().SendMessage(int64(42), third, "", gomock.Nil()).Return(12, nil),
+	)
+	ids, err = sender.Send(nf)
+	require.NoError(t, err)
+	require.Equal(t, []int{10, 11, 12}, ids)
+}
+
+func TestValidateForSenderLength(t *testing.T) {
+	long := strings.Repeat("я", telegramMaxMessageLength+1)
+
+	err := validateForSender(NewTelegramSender(nil, nil, nil), &models.Notification{Channel: models.ChannelTelegram, ChatId: 1, Message: long})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "allows at most 4096")
+
+	require.NoError(t, validateForSender(NewTelegramSender(nil, nil, nil), &models.Notification{ChatId: 1, Message: long, Split: true}))
+	require.NoError(t, validateForSender(NewTelegramSender(nil, nil, nil), &models.Notification{ChatId: 1, Message: strings.Repeat("я", telegramMaxMessageLength)}))
+	require.NoError(t, validateForSender(NewEmailSender(nil), &models.Notification{Recipient: "user@example.com", Message: long}))
+
+	err = validateForSender(&fakeSender{channel: "sms"}, &models.Notification{Channel: "sms", Recipient: "+100", Message: strings.Repeat("x", 40001), Split: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot split")
+}
+
+func TestValidateForSenderFormat(t *testing.T) {
+	require.NoError(t, validateForSender(NewTelegramSender(nil, nil, nil), &models.Notification{ChatId: 1, Format: models.FormatPlain, Message: "1 < 2"}))
+
+	err := validateForSender(NewEmailSender(nil), &models.Notification{Channel: models.ChannelEmail, Recipient: "user@example.com", Format: models.FormatHTML})
+	require.EqualError(t, err, "email channel does not support html format")
+
+	err = validateForSender(NewTelegramSender(nil, nil, nil), &models.Notification{ChatId: 1, Format: "markdown"})
+	require.EqualError(t, err, `unsupported format "markdown" (use plain, markdown_v2 or html)`)
+}
+
+func TestTelegramSenderParseMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := servicemocks.NewMockTelegramClientInterface(ctrl)
+	sender := NewTelegramSender(client, nil, nil)
+
+	client.EXPECT().SendMessage(int64(42), "<b>Standup</b>", "HTML", gomock.Nil()).Return(1, nil)
+	_, err := sender.Send(&models.Notification{ChatId: 42, Format: models.FormatHTML, Message: "<b>Standup</b>"})
+	require.NoError(t, err)
+
+	client.EXPECT().SendMessage(int64(42), "*Standup*", "MarkdownV2", gomock.Nil()).Return(2, nil)
+	_, err = sender.Send(&models.Notification{ChatId: 42, Format: models.FormatMarkdownV2, Message: "*Standup*"})
+	require.NoError(t, err)
+}
+
+func TestEmailSender_SendUsesBareAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := servicemocks.NewMockEmailClientInterface(ctrl)
+	client.EXPECT().SendEmail("user@example.com", "Standup", "In 10 minutes").Return(nil).Times(1)
+
+	_, err := NewEmailSender(client).Send(&models.Notification{
+		Recipient: "Jane Doe <user@example.com>",
+		Subject:   "Standup",
+		Message:   "In 10 minutes",
+	})
+	require.NoError(t, err)
+}
+
+func TestIsPermanent(t *testing.T) {
+	require.True(t, IsPermanent(Permanent(errors.New("blocked"))))
+	require.True(t, IsPermanent(fmt.Errorf("wrapped: %w", &webhook.StatusError{StatusCode: 404})))
+	require.False(t, IsPermanent(&webhook.StatusError{StatusCode: 503}))
+	require.False(t, IsPermanent(errors.New("timeout")))
+	require.Nil(t, Permanent(nil))
+}
+
+type rateLimitedError struct{}
+
+func (rateLimitedError) Error() string             { return "too many requests" }
+func (rateLimitedError) Retryable() bool           { return true }
+func (rateLimitedError) RetryDelay() time.Duration { return 17 * time.Second }
+
+func TestRetryAfter(t *testing.T) {
+	err := fmt.Errorf("send: %w", rateLimitedError{})
+	require.False(t, IsPermanent(err))
+	require.Equal(t, 17*time.Second, RetryAfter(err))
+	require.Zero(t, RetryAfter(errors.New("timeout")))
+}
+
+func TestDelayedNotifierService_ProcessNotificationCustomSender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+	slack := &fakeSender{channel: "slack"}
+
+	notification := &models.Notification{
+		Id:        "test-id",
+		Message:   "Test message",
+		Channel:   "slack",
+		Recipient: "#general",
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	repo.EXPECT().MarkNotificationSent("test-id", 0, "slack", int64(0), []int(nil), gomock.Nil()).Return(nil).Times(1)
+
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(nil, nil, nil), slack),
+		redis:   redisClient,
+		ctx:     setupTestContext(),
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.NoError(t, err)
+	require.Len(t, slack.sent, 1)
+	require.Equal(t, "#general", slack.sent[0].Recipient)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/pkg/logger"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// EditSentMessage changes the text, and optionally the format, of a
+// notification that has already been delivered, both in the chat and in the
+// stored notification.
+func (service *DelayedNotifierService) EditSentMessage(id string, req *models.Notification) (*models.Notification, error) {
+	if req.Message == "" {
+		return nil, models.Invalid(errors.New("message is required"))
+	}
+	nf, sender, err := service.sentNotification(id, "edited")
+	if err != nil {
+		return nil, err
+	}
+
+	edited := *nf
+	edited.Message = req.Message
+	if req.Format != "" {
+		edited.Format = req.Format
+	}
+	edited.Split = false
+	if err := validateForSender(sender, &edited); err != nil {
+		return nil, models.Invalid(err)
+	}
+
+	if err := sender.(MessageEditor).Edit(&edited); err != nil {
+		return nil, fmt.Errorf("failed to edit sent message: %w", err)
+	}
+	updated, err := service.repo.UpdateSentMessage(id, edited.Message, edited.Format)
+	if err != nil {
+		return nil, err
+	}
+	service.forgetNotification(id)
+
+	logger.GetLoggerFromCtx(service.ctx).Info("Sent notification edited",
+		zap.String("notification_id", id))
+	return updated, nil
+}
+
+// RetractNotification deletes the delivered messages of a notification from
+// the chat and marks it retracted.
+func (service *DelayedNotifierService) RetractNotification(id string) error {
+	nf, sender, err := service.sentNotification(id, "retracted")
+	if err != nil {
+		return err
+	}
+
+	if err := sender.(MessageEditor).Retract(nf); err != nil {
+		return fmt.Errorf("failed to retract sent message: %w", err)
+	}
+	if err := service.repo.RetractNotification(id); err != nil {
+		return err
+	}
+
+	service.forgetNotification(id)
+	service.releaseAttachment(nf)
+
+	logger.GetLoggerFromCtx(service.ctx).Info("Notification retracted",
+		zap.String("notification_id", id),
+		zap.Int("messages", len(nf.MessageIds)))
+	return nil
+}
+
+// sentNotification loads a delivered notification together with the sender
+// it went through, which is always a MessageEditor.
+func (service *DelayedNotifierService) sentNotification(id, action string) (*models.Notification, Sender, error) {
+	if id == "" {
+		return nil, nil, models.Invalid(errors.New("invalid id"))
+	}
+	nf, err := service.repo.GetNotification(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if nf.Status != models.StatusSent {
+		return nil, nil, models.Invalid(fmt.Errorf("notification %s cannot be %s in status %s", id, action, nf.Status))
+	}
+
+	sender, err := service.senders.Get(nf.DeliveredVia)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := sender.(MessageEditor); !ok {
+		return nil, nil, models.Invalid(fmt.Errorf("messages sent via %s cannot be %s", nf.DeliveredVia, action))
+	}
+	if len(nf.MessageIds) == 0 {
+		return nil, nil, fmt.Errorf("notification %s has no sent messages", id)
+	}
+	if nf.DeliveredChatId == 0 {
+		return nil, nil, models.Invalid(fmt.Errorf("the chat notification %s was delivered to is unknown, its messages cannot be %s", id, action))
+	}
+
+	// The messages live in the chat they were delivered to, and a fallback
+	// step sends them without buttons.
+	if nf.DeliveredVia != nf.Channel || nf.DeliveredChatId != nf.ChatId {
+		nf.ChatId = nf.DeliveredChatId
+		nf.Buttons = nil
+	}
+	return nf, sender, nil
+}
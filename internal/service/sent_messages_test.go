@@ -0,0 +1,177 @@
+package service
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/repository/mocks"
+	servicemocks "DelayedNotifier/internal/service/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTelegramSenderEdit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := servicemocks.NewMockTelegramClientInterface(ctrl)
+	sender := NewTelegramSender(client, nil, nil)
+
+	client.EXPECT().EditMessageText(int64(42), 7, "<b>Moved</b>", "HTML", gomock.Not(gomock.Nil())).Return(nil).Times(1)
+	require.NoError(t, sender.Edit(&models.Notification{
+		ChatId:     42,
+		Message:    "<b>Moved</b>",
+		Format:     models.FormatHTML,
+		Buttons:    [][]models.Button{{{Text: "Done", Action: models.ActionAck}}},
+		MessageIds: []int{7},
+	}))
+
+	// The keyboard is gone once a button was pressed.
+	client.EXPECT().EditMessageText(int64(42), 7, "Moved", "", gomock.Nil()).Return(nil).Times(1)
+	require.NoError(t, sender.Edit(&models.Notification{
+		ChatId:         42,
+		Message:        "Moved",
+		Buttons:        [][]models.Button{{{Text: "Done", Action: models.ActionAck}}},
+		AcknowledgedAt: "2026-03-08T09:01:00Z",
+		MessageIds:     []int{7},
+	}))
+
+	photo := &models.Attachment{Type: models.AttachmentPhoto, URL: "https://example.com/cat.jpg"}
+	client.EXPECT().EditMessageCaption(int64(42), 8, "New caption", "", gomock.Nil()).Return(nil).Times(1)
+	require.NoError(t, sender.Edit(&models.Notification{ChatId: 42, Message: "New caption", Attachment: photo, MessageIds: []int{8}}))
+
+	client.EXPECT().EditMessageText(int64(42), 10, "New text", "", gomock.Nil()).Return(nil).Times(1)
+	require.NoError(t, sender.Edit(&models.Notification{ChatId: 42, Message: "New text", Attachment: photo, MessageIds: []int{9, 10}}))
+
+	err := sender.Edit(&models.Notification{ChatId: 42, Message: "New text", MessageIds: []int{11, 12}})
+	require.ErrorContains(t, err, "only a message sent as a single part can be edited")
+}
+
+func TestDelayedNotifierService_EditSentMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	client := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		redis:   redisClient,
+		senders: NewSenderRegistry(NewTelegramSender(client, nil, nil), NewEmailSender(nil)),
+		ctx:     setupTestContext(),
+	}
+
+	sent := func() *models.Notification {
+		return &models.Notification{
+			Id:              "test-id",
+			Message:         "Standup at 10:00",
+			Status:          "sent",
+			ChatId:          42,
+			Channel:         models.ChannelTelegram,
+			Format:          models.FormatPlain,
+			DeliveredVia:    models.ChannelTelegram,
+			DeliveredChatId: 42,
+			MessageIds:      []int{7},
+		}
+	}
+
+	repo.EXPECT().GetNotification("test-id").Return(sent(), nil).Times(1)
+	client.EXPECT().EditMessageText(int64(42), 7, "*Standup at 11:00*", "MarkdownV2", gomock.Nil()).Return(nil).Times(1)
+	edited := sent()
+	edited.Message = "*Standup at 11:00*"
+	edited.Format = models.FormatMarkdownV2
+	repo.EXPECT().UpdateSentMessage("test-id", "*Standup at 11:00*", models.FormatMarkdownV2).Return(edited, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), "notification:record:test-id").Return(nil).Times(1)
+
+	nf, err := srv.EditSentMessage("test-id", &models.Notification{Message: "*Standup at 11:00*", Format: models.FormatMarkdownV2})
+	require.NoError(t, err)
+	require.Equal(t, edited, nf)
+
+	// Broken markup is rejected before Telegram is called.
+	repo.EXPECT().GetNotification("test-id").Return(sent(), nil).Times(1)
+	_, err = srv.EditSentMessage("test-id", &models.Notification{Message: "*Standup", Format: models.FormatMarkdownV2})
+	require.ErrorContains(t, err, "invalid markdown_v2 message")
+
+	pending := sent()
+	pending.Status = "created"
+	repo.EXPECT().GetNotification("test-id").Return(pending, nil).Times(1)
+	_, err = srv.EditSentMessage("test-id", &models.Notification{Message: "Standup at 11:00"})
+	require.EqualError(t, err, "notification test-id cannot be edited in status created")
+
+	email := sent()
+	email.DeliveredVia = models.ChannelEmail
+	repo.EXPECT().GetNotification("test-id").Return(email, nil).Times(1)
+	_, err = srv.EditSentMessage("test-id", &models.Notification{Message: "Standup at 11:00"})
+	require.EqualError(t, err, "messages sent via email cannot be edited")
+
+	_, err = srv.EditSentMessage("test-id", &models.Notification{})
+	require.EqualError(t, err, "message is required")
+}
+
+func TestDelayedNotifierService_RetractNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	client := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(client, nil, nil)),
+		redis:   redisClient,
+		ctx:     setupTestContext(),
+	}
+
+	repo.EXPECT().GetNotification("test-id").Return(&models.Notification{
+		Id:              "test-id",
+		Status:          "sent",
+		ChatId:          42,
+		DeliveredVia:    models.ChannelTelegram,
+		DeliveredChatId: 42,
+		MessageIds:      []int{7, 8},
+	}, nil).Times(1)
+	gomock.InOrder(
+		client.EXPECT().DeleteMessage(int64(42), 7).Return(nil),
+		client.EXPECT().DeleteMessage(int64(42), 8).Return(nil),
+		repo.EXPECT().RetractNotification("test-id").Return(nil),
+		redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil),
+	)
+	require.NoError(t, srv.RetractNotification("test-id"))
+
+	repo.EXPECT().GetNotification("test-id").Return(&models.Notification{
+		Id:           "test-id",
+		Status:       "sent",
+		ChatId:       42,
+		DeliveredVia: models.ChannelTelegram,
+	}, nil).Times(1)
+	require.EqualError(t, srv.RetractNotification("test-id"), "notification test-id has no sent messages")
+
+	// Messages delivered by a fallback target live in that target's chat.
+	repo.EXPECT().GetNotification("test-id").Return(&models.Notification{
+		Id:              "test-id",
+		Status:          "sent",
+		ChatId:          42,
+		Channel:         models.ChannelTelegram,
+		Fallback:        []models.FallbackTarget{{Channel: models.ChannelTelegram, ChatId: 77}},
+		DeliveredVia:    models.ChannelTelegram,
+		DeliveredChatId: 77,
+		MessageIds:      []int{9},
+	}, nil).Times(1)
+	gomock.InOrder(
+		client.EXPECT().DeleteMessage(int64(77), 9).Return(nil),
+		repo.EXPECT().RetractNotification("test-id").Return(nil),
+		redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil),
+	)
+	require.NoError(t, srv.RetractNotification("test-id"))
+
+	repo.EXPECT().GetNotification("test-id").Return(&models.Notification{
+		Id:           "test-id",
+		Status:       "sent",
+		ChatId:       42,
+		DeliveredVia: models.ChannelTelegram,
+		MessageIds:   []int{7},
+	}, nil).Times(1)
+	err := srv.RetractNotification("test-id")
+	require.ErrorContains(t, err, "was delivered to is unknown")
+	require.True(t, models.IsInvalid(err))
+}
@@ -1,11 +1,10 @@
 package service
 
-//go:generate mockgen -source=service.go -destination=mocks/mock_dependencies.go -package=mocks RabbitMQProducerInterface,TelegramClientInterface,RedisClientInterface
+//go:generate mockgen -source=service.go -destination=mocks/mock_dependencies.go -package=mocks RabbitMQProducerInterface,TelegramClientInterface,TelegramRateLimiterInterface,EmailClientInterface,WebhookClientInterface,RedisClientInterface,AttachmentStorageInterface
 //go:generate mockgen -source=service.go -destination=../repository/mocks/mock_repository.go -package=mocks NotificationRepositoryInterface
 
 import (
 	"DelayedNotifier/internal/models"
-	"DelayedNotifier/internal/rabbitmq"
 	"DelayedNotifier/internal/telegram"
 	"DelayedNotifier/pkg/logger"
 	"DelayedNotifier/pkg/redis"
@@ -13,7 +12,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/wb-go/wbf/config"
@@ -22,11 +23,27 @@ import (
 )
 
 type NotificationRepositoryInterface interface {
-	CreateNotification(notification *models.Notification) error
-	GetNotificationStatus(id string) (string, error)
-	DeleteNotification(id string) error
-	UpdateNotificationStatus(id string, status string) error
-	GetAllNotifications() ([]*models.Notification, error)
+	CreateNotification(notification *models.Notification, outbox *models.OutboxMessage) error
+	GetNotification(id string) (*models.Notification, error)
+	GetNotificationEvents(id string) ([]*models.NotificationEvent, error)
+	CancelNotification(id string) error
+	AcknowledgeNotification(id string, acknowledgedAt string) error
+	SnoozeNotification(id string, sendTime string, outbox *models.OutboxMessage) (*models.Notification, error)
+	RescheduleNotification(id string, sendTime string, message string, outbox *models.OutboxMessage) (*models.Notification, error)
+	MarkNotificationSending(id string, version int, lease time.Duration) (bool, error)
+	ExtendLease(id string, version int, lease time.Duration) (bool, error)
+	GetExpiredLeases(limit int) ([]*models.Notification, error)
+	ScheduleRetry(id string, version int, lastError string, outbox *models.OutboxMessage) (*models.Notification, error)
+	MarkNotificationFailed(id string, version int, lastError string, occurrence *models.Occurrence) error
+	MarkNotificationSent(id string, version int, channel string, chatId int64, messageIds []int, occurrence *models.Occurrence) error
+	SaveMessageIds(id string, version int, messageIds []int) error
+	UpdateSentMessage(id string, message string, format string) (*models.Notification, error)
+	RetractNotification(id string) error
+	ListNotifications(q *models.NotificationQuery) ([]*models.Notification, int, error)
+	GetPendingNotifications(chatId int64) ([]*models.Notification, error)
+	GetChatTimezone(chatId int64) (string, error)
+	SetChatTimezone(chatId int64, timezone string) error
+	ProcessOutbox(limit int, publish func(*models.OutboxMessage) error) (int, error)
 }
 
 type RabbitMQProducerInterface interface {
@@ -34,7 +51,31 @@ type RabbitMQProducerInterface interface {
 }
 
 type TelegramClientInterface interface {
-	SendMessage(chatID int64, text string) error
+	SendMessage(chatID int64, text, parseMode string, keyboard [][]telegram.Button) (int, error)
+	SendAttachment(chatID int64, attachment telegram.Attachment, caption, parseMode string, keyboard [][]telegram.Button) (int, error)
+	EditMessageText(chatID int64, messageID int, text, parseMode string, keyboard [][]telegram.Button) error
+	EditMessageCaption(chatID int64, messageID int, caption, parseMode string, keyboard [][]telegram.Button) error
+	DeleteMessage(chatID int64, messageID int) error
+}
+
+type TelegramRateLimiterInterface interface {
+	Wait(chatID int64) error
+}
+
+type EmailClientInterface interface {
+	SendEmail(to string, subject string, body string) error
+}
+
+type WebhookClientInterface interface {
+	SendWebhook(url string, headers map[string]string, payload []byte) error
+}
+
+// AttachmentStorageInterface keeps files uploaded with notifications until
+// they are sent. Keys are chosen by the storage.
+type AttachmentStorageInterface interface {
+	Save(name string, r io.Reader) (string, error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
 }
 
 type RedisClientInterface interface {
@@ -44,169 +85,355 @@ type RedisClientInterface interface {
 }
 
 type DelayedNotifierService struct {
-	repo           NotificationRepositoryInterface
-	ctx            context.Context
-	producer       RabbitMQProducerInterface
-	cfg            *config.Config
-	telegramClient TelegramClientInterface
-	redis          RedisClientInterface
+	repo        NotificationRepositoryInterface
+	ctx         context.Context
+	cfg         *config.Config
+	senders     *SenderRegistry
+	storage     AttachmentStorageInterface
+	redis       RedisClientInterface
+	retryPolicy RetryPolicy
+	lease       time.Duration
 }
 
-func New(producer *rabbitmq.Producer, repo NotificationRepositoryInterface, telegramClient *telegram.Client, redisClient *wbfredis.Client, ctx context.Context, cfg *config.Config) *DelayedNotifierService {
+func New(repo NotificationRepositoryInterface, senders *SenderRegistry, storage AttachmentStorageInterface, redisClient *wbfredis.Client, ctx context.Context, cfg *config.Config) *DelayedNotifierService {
 	return &DelayedNotifierService{
-		repo:           repo,
-		producer:       producer,
-		telegramClient: telegramClient,
-		redis:          redisClient,
-		ctx:            ctx,
-		cfg:            cfg,
+		repo:        repo,
+		senders:     senders,
+		storage:     storage,
+		redis:       redisClient,
+		ctx:         ctx,
+		cfg:         cfg,
+		retryPolicy: newRetryPolicy(cfg),
+		lease:       deliveryLease(cfg),
 	}
 }
 
 func (service *DelayedNotifierService) CreateNotification(nf *models.Notification) (string, error) {
-	nf.Id = uuid.New().String()
-
-	var delay time.Duration
+	if nf.Attachment != nil && nf.Attachment.FileKey != "" {
+		return "", models.Invalid(errors.New("attachment file_key cannot be set directly, upload the file instead"))
+	}
+	return service.createNotification(nf)
+}
 
-	if nf.Time == "" {
-		delay = 0
-	} else {
-		sendTime, err := time.Parse(time.RFC3339, nf.Time)
+func (service *DelayedNotifierService) createNotification(nf *models.Notification) (string, error) {
+	// Delivery state belongs to the service; a request cannot preset it,
+	// e.g. message_ids would make the sender skip parts never sent.
+	nf.Id = uuid.New().String()
+	nf.Version = 0
+	nf.Occurrences = 0
+	nf.Attempts = 0
+	nf.MessageIds = nil
+	nf.DeliveredVia = ""
+	nf.DeliveredChatId = 0
+	nf.AcknowledgedAt = ""
+	nf.LeaseUntil = nil
+
+	if nf.Channel == "" {
+		nf.Channel = models.ChannelTelegram
+	}
+	if nf.Format == "" {
+		nf.Format = models.FormatPlain
+	}
+	if err := service.validateNotification(nf); err != nil {
+		return "", models.Invalid(err)
+	}
+	if nf.Schedule != "" && nf.Time == "" {
+		first, ok, err := nextOccurrence(nf, 0, time.Now())
 		if err != nil {
-			return "", fmt.Errorf("invalid time format (use RFC3339): %w", err)
+			return "", models.Invalid(err)
 		}
-		delay = time.Until(sendTime)
-		if delay < 0 {
-			delay = 0
+		if !ok {
+			return "", models.Invalid(errors.New("schedule has no occurrences before end_at"))
 		}
+		nf.Time = first.Format(time.RFC3339)
 	}
-	data, err := json.Marshal(nf)
+
+	sendAt, err := parseSendTime(nf.Time)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal notification: %w", err)
+		return "", models.Invalid(err)
 	}
-	if err = service.producer.Publish(data, service.ctx, service.cfg.GetString("ROUTING_KEY"), delay); err != nil {
+	nf.Status = models.StatusCreated
+	nf.SendAt = sendAt
+	nf.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	nf.SentAt = nil
+	nf.LastError = ""
+	err = service.repo.CreateNotification(nf, &models.OutboxMessage{
+		RoutingKey: service.cfg.GetString("ROUTING_KEY"),
+		SendAt:     sendAt,
+	})
+	if err != nil {
 		return "", err
 	}
-	nf.Status = "created"
-	err = service.repo.CreateNotification(nf)
+
+	service.cacheNotification(nf)
+
+	return nf.Id, nil
+}
+
+func (service *DelayedNotifierService) validateNotification(nf *models.Notification) error {
+	if err := service.validateChannel(nf); err != nil {
+		return err
+	}
+	if err := service.validateButtons(nf); err != nil {
+		return err
+	}
+	if err := service.validateFallback(nf); err != nil {
+		return err
+	}
+	if err := validateRecurrence(nf); err != nil {
+		return err
+	}
+	return validateRetryPolicy(nf)
+}
+
+func (service *DelayedNotifierService) RescheduleNotification(id string, req *models.Notification) (*models.Notification, error) {
+	if id == "" {
+		return nil, models.Invalid(errors.New("invalid id"))
+	}
+	if req.Time == "" {
+		return nil, models.Invalid(errors.New("time is required"))
+	}
+
+	sendAt, err := parseSendTime(req.Time)
 	if err != nil {
-		return "", err
+		return nil, models.Invalid(err)
 	}
 
-	logger.GetLoggerFromCtx(service.ctx).Info("Attempting to cache notification status",
-		zap.String("notification_id", nf.Id),
-		zap.String("status", nf.Status),
-		zap.String("key", redis.CacheKey(nf.Id)))
+	// A new message must pass the same checks as on create, against the
+	// channel, format and fallback chain stored with the notification.
+	if req.Message != "" {
+		current, err := service.repo.GetNotification(id)
+		if err != nil {
+			return nil, err
+		}
+		edited := *current
+		edited.Message = req.Message
+		if err := service.validateChannel(&edited); err != nil {
+			return nil, models.Invalid(err)
+		}
+		if err := service.validateFallback(&edited); err != nil {
+			return nil, models.Invalid(err)
+		}
+	}
 
-	if err = service.redis.SetWithExpiration(service.ctx, redis.CacheKey(nf.Id), nf.Status, redis.StatusCacheTTL); err != nil {
-		logger.GetLoggerFromCtx(service.ctx).Error("Failed to cache notification status",
-			zap.Error(err),
-			zap.String("notification_id", nf.Id))
-	} else {
-		logger.GetLoggerFromCtx(service.ctx).Info("Successfully cached notification status",
-			zap.String("notification_id", nf.Id))
+	nf, err := service.repo.RescheduleNotification(id, req.Time, req.Message, &models.OutboxMessage{
+		RoutingKey: service.cfg.GetString("ROUTING_KEY"),
+		SendAt:     sendAt,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nf.Id, nil
+	service.forgetNotification(nf.Id)
+
+	logger.GetLoggerFromCtx(service.ctx).Info("Notification rescheduled",
+		zap.String("notification_id", nf.Id),
+		zap.Int("version", nf.Version),
+		zap.Time("send_at", sendAt))
+
+	return nf, nil
 }
 
-func (service *DelayedNotifierService) GetNotificationStatus(id string) (string, error) {
+// GetNotification returns the whole stored notification, from the cache
+// when it has been read recently.
+func (service *DelayedNotifierService) GetNotification(id string) (*models.Notification, error) {
 	if id == "" {
-		return "", errors.New("invalid id")
+		return nil, errors.New("invalid id")
 	}
 
-	status, err := service.redis.Get(service.ctx, redis.CacheKey(id))
+	cached, err := service.redis.Get(service.ctx, redis.CacheKey(id))
 	if err == nil {
-		logger.GetLoggerFromCtx(service.ctx).Debug("Notification status retrieved from cache",
-			zap.String("notification_id", id),
-			zap.String("status", status))
-		return status, nil
+		var nf models.Notification
+		if err = json.Unmarshal([]byte(cached), &nf); err == nil {
+			logger.GetLoggerFromCtx(service.ctx).Debug("Notification retrieved from cache",
+				zap.String("notification_id", id),
+				zap.String("status", string(nf.Status)))
+			return &nf, nil
+		}
+		logger.GetLoggerFromCtx(service.ctx).Warn("Ignoring unreadable cached notification",
+			zap.Error(err),
+			zap.String("notification_id", id))
 	}
 
-	status, err = service.repo.GetNotificationStatus(id)
+	nf, err := service.repo.GetNotification(id)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	service.cacheNotification(nf)
+	return nf, nil
+}
+
+// cacheNotification stores the serialized notification under its cache key.
+func (service *DelayedNotifierService) cacheNotification(nf *models.Notification) {
+	data, err := json.Marshal(nf)
+	if err == nil {
+		err = service.redis.SetWithExpiration(service.ctx, redis.CacheKey(nf.Id), string(data), cacheTTL(nf))
+	}
+	if err != nil {
+		logger.GetLoggerFromCtx(service.ctx).Warn("Failed to cache notification",
+			zap.Error(err),
+			zap.String("notification_id", nf.Id))
 	}
+}
 
-	if err := service.redis.SetWithExpiration(service.ctx, redis.CacheKey(id), status, redis.StatusCacheTTL); err != nil {
-		logger.GetLoggerFromCtx(service.ctx).Warn("Failed to cache notification status after DB read",
+// cacheTTL keeps notifications waiting for or in delivery only briefly:
+// their status changes without a request, and a read racing with that change
+// would otherwise put the old status back into the cache for an hour.
+func cacheTTL(nf *models.Notification) time.Duration {
+	if nf.Status == models.StatusCreated || nf.Status == models.StatusSending {
+		return redis.PendingCacheTTL
+	}
+	return redis.CacheTTL
+}
+
+// forgetNotification drops the cached copy of a notification that has just
+// changed in the database, so the next read loads it again.
+func (service *DelayedNotifierService) forgetNotification(id string) {
+	if err := service.redis.Del(service.ctx, redis.CacheKey(id)); err != nil {
+		logger.GetLoggerFromCtx(service.ctx).Warn("Failed to drop notification from cache",
 			zap.Error(err),
 			zap.String("notification_id", id))
 	}
-
-	return status, nil
 }
 
 func (service *DelayedNotifierService) DeleteNotification(id string) error {
 	if id == "" {
-		return errors.New("invalid id")
+		return models.Invalid(errors.New("invalid id"))
 	}
-	err := service.repo.DeleteNotification(id)
+	err := service.repo.CancelNotification(id)
 	if err != nil {
 		return err
 	}
 
-	if err := service.redis.Del(service.ctx, redis.CacheKey(id)); err != nil {
-		logger.GetLoggerFromCtx(service.ctx).Warn("Failed to delete status from cache",
+	if err := service.redis.SetWithExpiration(service.ctx, redis.TombstoneKey(id), string(models.StatusCancelled), redis.TombstoneTTL); err != nil {
+		logger.GetLoggerFromCtx(service.ctx).Warn("Failed to set cancellation tombstone",
 			zap.Error(err),
 			zap.String("notification_id", id))
 	}
 
+	service.forgetNotification(id)
+	if service.storage != nil {
+		if nf, err := service.repo.GetNotification(id); err == nil {
+			service.releaseAttachment(nf)
+		} else {
+			logger.GetLoggerFromCtx(service.ctx).Warn("Failed to load cancelled notification to delete its attachment",
+				zap.Error(err),
+				zap.String("notification_id", id))
+		}
+	}
+
+	logger.GetLoggerFromCtx(service.ctx).Info("Notification cancelled",
+		zap.String("notification_id", id))
 	return nil
 }
 
+func (service *DelayedNotifierService) isCancelled(id string) bool {
+	_, err := service.redis.Get(service.ctx, redis.TombstoneKey(id))
+	return err == nil
+}
+
 func (service *DelayedNotifierService) ProcessNotification(nf *models.Notification) error {
-	if nf.Id == "" || nf.Message == "" || nf.ChatId == 0 {
+	if nf.Id == "" || (nf.Message == "" && len(nf.Payload) == 0 && nf.Attachment == nil) {
 		return errors.New("invalid notification: missing required fields")
 	}
-
-	if err := service.repo.UpdateNotificationStatus(nf.Id, "sending"); err != nil {
-		return fmt.Errorf("failed to update status to sending: %w", err)
+	if nf.Channel == "" {
+		nf.Channel = models.ChannelTelegram
+	}
+	if nf.Format == "" {
+		nf.Format = models.FormatPlain
+	}
+	if err := service.validateChannel(nf); err != nil {
+		return fmt.Errorf("invalid notification: %w", err)
 	}
 
-	if err := service.redis.SetWithExpiration(service.ctx, redis.CacheKey(nf.Id), "sending", redis.StatusCacheTTL); err != nil {
-		logger.GetLoggerFromCtx(service.ctx).Warn("Failed to update status in cache",
-			zap.Error(err),
+	if service.isCancelled(nf.Id) {
+		logger.GetLoggerFromCtx(service.ctx).Info("Skipping cancelled notification",
 			zap.String("notification_id", nf.Id))
+		return nil
 	}
 
-	logger.GetLoggerFromCtx(service.ctx).Info("Sending notification to Telegram",
+	// The conditional update is what guarantees a cancelled or rescheduled
+	// notification is never delivered: the tombstone above is only a shortcut.
+	claimed, err := service.repo.MarkNotificationSending(nf.Id, nf.Version, service.lease)
+	if err != nil {
+		return fmt.Errorf("failed to update status to sending: %w", err)
+	}
+	if !claimed {
+		logger.GetLoggerFromCtx(service.ctx).Info("Skipping notification that is cancelled, stale, already sent or being sent by another worker",
+			zap.String("notification_id", nf.Id),
+			zap.Int("version", nf.Version))
+		return nil
+	}
+
+	service.forgetNotification(nf.Id)
+
+	logger.GetLoggerFromCtx(service.ctx).Info("Sending notification",
 		zap.String("notification_id", nf.Id),
+		zap.String("channel", nf.Channel),
 		zap.Int64("chat_id", nf.ChatId),
+		zap.String("recipient", nf.Recipient),
 		zap.String("message", nf.Message))
 
-	err := service.telegramClient.SendMessage(nf.ChatId, nf.Message)
+	stopLease := service.keepLease(nf)
+	sent, messageIds, err := service.deliver(nf)
+	stopLease()
 	if err != nil {
-		logger.GetLoggerFromCtx(service.ctx).Error("Failed to send telegram message",
+		attempt := nf.Attempts + 1
+		if sent.Channel == nf.Channel && sent.ChatId == nf.ChatId && len(messageIds) > len(nf.MessageIds) {
+			if err := service.repo.SaveMessageIds(nf.Id, nf.Version, messageIds); err != nil {
+				logger.GetLoggerFromCtx(service.ctx).Error("Failed to save delivered parts",
+					zap.Error(err),
+					zap.String("notification_id", nf.Id))
+			}
+		}
+		logger.GetLoggerFromCtx(service.ctx).Error("Failed to send notification",
 			zap.Error(err),
-			zap.String("notification_id", nf.Id))
+			zap.String("channel", nf.Channel),
+			zap.String("notification_id", nf.Id),
+			zap.Int("attempt", attempt))
+
+		if !IsPermanent(err) {
+			retryAt, ok := service.retryPolicy.forNotification(nf).next(nf, attempt, time.Now(), RetryAfter(err))
+			if ok {
+				return service.scheduleRetry(nf, retryAt, err)
+			}
+		}
 
-		if updateErr := service.repo.UpdateNotificationStatus(nf.Id, "failed"); updateErr != nil {
+		occurrence := service.finishOccurrence(nf)
+		if updateErr := service.repo.MarkNotificationFailed(nf.Id, nf.Version, err.Error(), occurrence); updateErr != nil {
 			logger.GetLoggerFromCtx(service.ctx).Error("Failed to update notification status to failed",
 				zap.Error(updateErr))
 			return updateErr
 		}
 
-		if err := service.redis.SetWithExpiration(service.ctx, redis.CacheKey(nf.Id), "failed", redis.StatusCacheTTL); err != nil {
-			logger.GetLoggerFromCtx(service.ctx).Warn("Failed to update status in cache",
-				zap.Error(err),
-				zap.String("notification_id", nf.Id))
+		service.forgetNotification(nf.Id)
+		if lastDelivery(nf, occurrence, false) {
+			service.releaseAttachment(nf)
 		}
 
-		return fmt.Errorf("failed to send telegram message: %w", err)
+		return fmt.Errorf("failed to send %s message: %w", nf.Channel, err)
 	}
 
-	logger.GetLoggerFromCtx(service.ctx).Info("Telegram send succeeded",
+	logger.GetLoggerFromCtx(service.ctx).Info("Send succeeded",
+		zap.String("channel", sent.Channel),
 		zap.String("notification_id", nf.Id))
 
-	if err = service.repo.UpdateNotificationStatus(nf.Id, "sent"); err != nil {
+	nf.DeliveredVia = sent.Channel
+	nf.MessageIds = messageIds
+	// The chat is only kept to scope the message ids, which may belong to a
+	// fallback target's chat.
+	nf.DeliveredChatId = 0
+	if len(messageIds) > 0 {
+		nf.DeliveredChatId = sent.ChatId
+	}
+	occurrence := service.finishOccurrence(nf)
+	if err = service.repo.MarkNotificationSent(nf.Id, nf.Version, nf.DeliveredVia, nf.DeliveredChatId, messageIds, occurrence); err != nil {
 		return fmt.Errorf("failed to update status to sent: %w", err)
 	}
 
-	if err := service.redis.SetWithExpiration(service.ctx, redis.CacheKey(nf.Id), "sent", redis.StatusCacheTTL); err != nil {
-		logger.GetLoggerFromCtx(service.ctx).Warn("Failed to update status in cache",
-			zap.Error(err),
-			zap.String("notification_id", nf.Id))
+	service.forgetNotification(nf.Id)
+	if lastDelivery(nf, occurrence, true) {
+		service.releaseAttachment(nf)
 	}
 
 	logger.GetLoggerFromCtx(service.ctx).Info("Notification sent successfully",
@@ -214,6 +441,170 @@ func (service *DelayedNotifierService) ProcessNotification(nf *models.Notificati
 	return nil
 }
 
-func (service *DelayedNotifierService) GetAllNotifications() ([]*models.Notification, error) {
-	return service.repo.GetAllNotifications()
+func (service *DelayedNotifierService) scheduleRetry(nf *models.Notification, retryAt time.Time, sendErr error) error {
+	_, err := service.repo.ScheduleRetry(nf.Id, nf.Version, sendErr.Error(), &models.OutboxMessage{
+		RoutingKey: service.cfg.GetString("ROUTING_KEY"),
+		SendAt:     retryAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule retry: %w", err)
+	}
+
+	service.forgetNotification(nf.Id)
+
+	logger.GetLoggerFromCtx(service.ctx).Info("Notification retry scheduled",
+		zap.String("notification_id", nf.Id),
+		zap.Int("attempt", nf.Attempts+1),
+		zap.Time("retry_at", retryAt))
+	return nil
+}
+
+// deliver sends nf over its channel and, while failures are permanent, over
+// each fallback target in order. It returns the last notification tried, nf
+// or a fallback step with its own channel and chat, and the message ids it
+// produced, including those of a partially sent split message.
+func (service *DelayedNotifierService) deliver(nf *models.Notification) (*models.Notification, []int, error) {
+	ids, err := service.send(nf)
+	if err == nil {
+		return nf, ids, nil
+	}
+
+	sent := nf
+	for _, target := range nf.Fallback {
+		if !IsPermanent(err) {
+			break
+		}
+		step := withFallbackTarget(nf, target)
+		logger.GetLoggerFromCtx(service.ctx).Warn("Channel failed permanently, falling back",
+			zap.Error(err),
+			zap.String("notification_id", nf.Id),
+			zap.String("next_channel", step.Channel))
+
+		sent = step
+		ids, err = service.send(step)
+		if err == nil {
+			break
+		}
+	}
+	return sent, ids, err
+}
+
+func (service *DelayedNotifierService) send(nf *models.Notification) ([]int, error) {
+	sender, err := service.senders.Get(nf.Channel)
+	if err != nil {
+		return nil, Permanent(err)
+	}
+	if err := validateForSender(sender, nf); err != nil {
+		return nil, Permanent(err)
+	}
+	return sender.Send(nf)
+}
+
+// finishOccurrence describes how the current firing of recurring nf ends, so
+// the repository can queue the next one together with the final status. It
+// returns nil for a one-off notification.
+func (service *DelayedNotifierService) finishOccurrence(nf *models.Notification) *models.Occurrence {
+	if nf.Schedule == "" {
+		return nil
+	}
+
+	fired := nf.Occurrences + 1
+	next, ok, err := nextOccurrence(nf, fired, time.Now())
+	if err != nil {
+		logger.GetLoggerFromCtx(service.ctx).Error("Failed to compute next occurrence, ending the series",
+			zap.Error(err),
+			zap.String("notification_id", nf.Id))
+		return &models.Occurrence{}
+	}
+	if !ok {
+		logger.GetLoggerFromCtx(service.ctx).Info("Recurring notification finished",
+			zap.String("notification_id", nf.Id),
+			zap.Int("occurrences", fired))
+		return &models.Occurrence{}
+	}
+
+	return &models.Occurrence{
+		NextTime: next.Format(time.RFC3339),
+		Outbox: &models.OutboxMessage{
+			RoutingKey: service.cfg.GetString("ROUTING_KEY"),
+			SendAt:     next,
+		},
+	}
+}
+
+func (service *DelayedNotifierService) validateChannel(nf *models.Notification) error {
+	sender, err := service.senders.Get(nf.Channel)
+	if err != nil {
+		return err
+	}
+	return validateForSender(sender, nf)
+}
+
+func validateForSender(sender Sender, nf *models.Notification) error {
+	caps := sender.Capabilities()
+	if nf.Attachment != nil && !caps.Attachments {
+		return fmt.Errorf("%s channel does not support attachments", nf.Channel)
+	}
+	switch nf.Format {
+	case "", models.FormatPlain:
+	case models.FormatMarkdownV2, models.FormatHTML:
+		if !caps.Formatting {
+			return fmt.Errorf("%s channel does not support %s format", nf.Channel, nf.Format)
+		}
+	default:
+		return fmt.Errorf("unsupported format %q (use plain, markdown_v2 or html)", nf.Format)
+	}
+
+	if err := sender.Validate(nf); err != nil {
+		return err
+	}
+
+	length := utf8.RuneCountInString(nf.Message)
+	if caps.MaxLength > 0 && length > caps.MaxLength {
+		if !nf.Split {
+			return fmt.Errorf("message is %d characters long, %s channel allows at most %d (set split to send it in parts)", length, nf.Channel, caps.MaxLength)
+		}
+		if !caps.Splitting {
+			return fmt.Errorf("message is %d characters long and %s channel cannot split messages", length, nf.Channel)
+		}
+	}
+	return nil
+}
+
+func (service *DelayedNotifierService) validateFallback(nf *models.Notification) error {
+	for i, target := range nf.Fallback {
+		if target.Channel == "" {
+			return fmt.Errorf("invalid fallback %d: channel is required", i)
+		}
+		if err := service.validateChannel(withFallbackTarget(nf, target)); err != nil {
+			return fmt.Errorf("invalid fallback %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func withFallbackTarget(nf *models.Notification, target models.FallbackTarget) *models.Notification {
+	step := *nf
+	step.Channel = target.Channel
+	step.MessageIds = nil
+	// Buttons act on the original chat only.
+	step.Buttons = nil
+	if target.ChatId != 0 {
+		step.ChatId = target.ChatId
+	}
+	if target.Recipient != "" {
+		step.Recipient = target.Recipient
+	}
+	return &step
+}
+
+func parseSendTime(sendTime string) (time.Time, error) {
+	if sendTime == "" {
+		return time.Now(), nil
+	}
+	t, err := time.Parse(time.RFC3339, sendTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time format (use RFC3339): %w", err)
+	}
+	return t, nil
 }
@@ -5,9 +5,13 @@ import (
 	"DelayedNotifier/internal/repository/mocks"
 	servicemocks "DelayedNotifier/internal/service/mocks"
 	"DelayedNotifier/pkg/logger"
+	"DelayedNotifier/pkg/redis"
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/wb-go/wbf/config"
@@ -23,11 +27,16 @@ func setupTestContext() context.Context {
 	return ctx
 }
 
+// testSenders registers every built-in channel without clients, which is
+// enough for tests that never reach delivery.
+func testSenders() *SenderRegistry {
+	return NewSenderRegistry(NewTelegramSender(nil, nil, nil), NewEmailSender(nil), NewWebhookSender(nil))
+}
+
 func TestDelayedNotifierService_CreateNotificationSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
-	producer := servicemocks.NewMockRabbitMQProducerInterface(ctrl)
 	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
 
 	inputNotification := &models.Notification{
@@ -36,9 +45,13 @@ func TestDelayedNotifierService_CreateNotificationSuccess(t *testing.T) {
 		ChatId:  123456789,
 	}
 
-	producer.EXPECT().Publish(gomock.Any(), gomock.Any(), "test.routing.key", gomock.Any()).Return(nil).Times(1)
-	repo.EXPECT().CreateNotification(gomock.Any()).Return(nil).Times(1)
-	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), "created", gomock.Any()).Return(nil).Times(1)
+	repo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(nf *models.Notification, outbox *models.OutboxMessage) error {
+			require.Equal(t, "test.routing.key", outbox.RoutingKey)
+			require.Equal(t, "2026-02-13T15:00:00+03:00", outbox.SendAt.Format(time.RFC3339))
+			return nil
+		}).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 	ctx := setupTestContext()
 	cfg := config.New()
@@ -46,18 +59,62 @@ func TestDelayedNotifierService_CreateNotificationSuccess(t *testing.T) {
 	cfg.SetDefault("ROUTING_KEY", "test.routing.key")
 
 	srv := &DelayedNotifierService{
-		repo:     repo,
-		producer: producer,
-		redis:    redisClient,
-		ctx:      ctx,
-		cfg:      cfg,
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
+		cfg:     cfg,
 	}
 
 	id, err := srv.CreateNotification(inputNotification)
 	require.NoError(t, err)
 	require.NotEmpty(t, id)
 	require.Equal(t, id, inputNotification.Id)
-	require.Equal(t, "created", inputNotification.Status)
+	require.Equal(t, models.StatusCreated, inputNotification.Status)
+}
+
+func TestDelayedNotifierService_CreateNotificationClearsDeliveryState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	leaseUntil := time.Now()
+	request := &models.Notification{
+		Message:        "Test notification",
+		Time:           "2026-02-13T15:00:00+03:00",
+		ChatId:         123456789,
+		MessageIds:     []int{99},
+		DeliveredVia:   models.ChannelTelegram,
+		AcknowledgedAt: "2026-02-13T15:01:00+03:00",
+		LeaseUntil:     &leaseUntil,
+		Attempts:       2,
+	}
+
+	repo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(nf *models.Notification, outbox *models.OutboxMessage) error {
+			require.Empty(t, nf.MessageIds)
+			require.Empty(t, nf.DeliveredVia)
+			require.Empty(t, nf.AcknowledgedAt)
+			require.Nil(t, nf.LeaseUntil)
+			require.Zero(t, nf.Attempts)
+			return nil
+		}).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	cfg := config.New()
+	cfg.EnableEnv("")
+	cfg.SetDefault("ROUTING_KEY", "test.routing.key")
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     setupTestContext(),
+		cfg:     cfg,
+	}
+
+	_, err := srv.CreateNotification(request)
+	require.NoError(t, err)
 }
 
 func TestDelayedNotifierService_CreateNotificationInvalidTime(t *testing.T) {
@@ -65,7 +122,6 @@ func TestDelayedNotifierService_CreateNotificationInvalidTime(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
-	producer := servicemocks.NewMockRabbitMQProducerInterface(ctrl)
 	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
 
 	inputNotification := &models.Notification{
@@ -78,25 +134,141 @@ func TestDelayedNotifierService_CreateNotificationInvalidTime(t *testing.T) {
 	cfg := &config.Config{}
 
 	srv := &DelayedNotifierService{
-		repo:     repo,
-		producer: producer,
-		redis:    redisClient,
-		ctx:      ctx,
-		cfg:      cfg,
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
+		cfg:     cfg,
 	}
 
 	id, err := srv.CreateNotification(inputNotification)
 	require.Error(t, err)
 	require.Empty(t, id)
 	require.Contains(t, err.Error(), "invalid time format")
+	require.True(t, models.IsInvalid(err))
+}
+
+func TestDelayedNotifierService_CreateNotificationRecurring(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	inputNotification := &models.Notification{
+		Message:        "Take your pills",
+		ChatId:         123456789,
+		Schedule:       "every 8h",
+		MaxOccurrences: 21,
+	}
+
+	repo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	cfg := config.New()
+	cfg.EnableEnv("")
+
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
+		cfg:     cfg,
+	}
+
+	_, err := srv.CreateNotification(inputNotification)
+	require.NoError(t, err)
+	first, err := time.Parse(time.RFC3339, inputNotification.Time)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(8*time.Hour), first, time.Minute)
 }
 
-func TestDelayedNotifierService_CreateNotificationPublishError(t *testing.T) {
+func TestDelayedNotifierService_CreateNotificationInvalidSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: testSenders(),
+		ctx:     ctx,
+		cfg:     &config.Config{},
+	}
+
+	id, err := srv.CreateNotification(&models.Notification{Message: "x", ChatId: 1, Schedule: "sometimes"})
+	require.Error(t, err)
+	require.Empty(t, id)
+	require.Contains(t, err.Error(), "invalid schedule")
+}
+
+func TestDelayedNotifierService_CreateNotificationInvalidEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: testSenders(),
+		ctx:     ctx,
+		cfg:     &config.Config{},
+	}
+
+	id, err := srv.CreateNotification(&models.Notification{Message: "x", Channel: models.ChannelEmail, Recipient: "not-an-email"})
+	require.Error(t, err)
+	require.Empty(t, id)
+	require.Contains(t, err.Error(), "invalid email recipient")
+	require.True(t, models.IsInvalid(err))
+}
+
+func TestDelayedNotifierService_CreateNotificationInvalidWebhookURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: testSenders(),
+		ctx:     ctx,
+		cfg:     &config.Config{},
+	}
+
+	id, err := srv.CreateNotification(&models.Notification{Message: "x", Channel: models.ChannelWebhook, Recipient: "ftp://example.com/hook"})
+	require.Error(t, err)
+	require.Empty(t, id)
+	require.Contains(t, err.Error(), "invalid webhook url")
+}
+
+func TestDelayedNotifierService_CreateNotificationInvalidFallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: testSenders(),
+		ctx:     ctx,
+		cfg:     &config.Config{},
+	}
+
+	id, err := srv.CreateNotification(&models.Notification{
+		Message:  "x",
+		ChatId:   1,
+		Fallback: []models.FallbackTarget{{Channel: models.ChannelEmail}},
+	})
+	require.Error(t, err)
+	require.Empty(t, id)
+	require.Contains(t, err.Error(), "invalid fallback 0: recipient is required")
+}
+
+func TestDelayedNotifierService_CreateNotificationRepoError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
-	producer := servicemocks.NewMockRabbitMQProducerInterface(ctrl)
 	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
 
 	inputNotification := &models.Notification{
@@ -105,8 +277,8 @@ func TestDelayedNotifierService_CreateNotificationPublishError(t *testing.T) {
 		ChatId:  123456789,
 	}
 
-	expectedErr := errors.New("rabbitmq publish error")
-	producer.EXPECT().Publish(gomock.Any(), gomock.Any(), "test.routing.key", gomock.Any()).Return(expectedErr).Times(1)
+	expectedErr := errors.New("database error")
+	repo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(expectedErr).Times(1)
 
 	ctx := setupTestContext()
 	cfg := config.New()
@@ -114,11 +286,11 @@ func TestDelayedNotifierService_CreateNotificationPublishError(t *testing.T) {
 	cfg.SetDefault("ROUTING_KEY", "test.routing.key")
 
 	srv := &DelayedNotifierService{
-		repo:     repo,
-		producer: producer,
-		redis:    redisClient,
-		ctx:      ctx,
-		cfg:      cfg,
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
+		cfg:     cfg,
 	}
 
 	id, err := srv.CreateNotification(inputNotification)
@@ -127,7 +299,7 @@ func TestDelayedNotifierService_CreateNotificationPublishError(t *testing.T) {
 	require.Equal(t, expectedErr, err)
 }
 
-func TestDelayedNotifierService_GetNotificationStatusFromCache(t *testing.T) {
+func TestDelayedNotifierService_RescheduleNotificationSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -135,23 +307,127 @@ func TestDelayedNotifierService_GetNotificationStatusFromCache(t *testing.T) {
 	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
 
 	notifID := "test-id-123"
-	expectedStatus := "created"
+	newTime := "2026-02-13T16:00:00+03:00"
+	updated := &models.Notification{
+		Id:      notifID,
+		Message: "Snoozed",
+		Time:    newTime,
+		Status:  "created",
+		ChatId:  123456789,
+		Version: 1,
+	}
 
-	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return(expectedStatus, nil).Times(1)
+	repo.EXPECT().GetNotification(notifID).Return(&models.Notification{Id: notifID, Message: "Call", Status: "created", ChatId: 123456789, Channel: models.ChannelTelegram}, nil).Times(1)
+	repo.EXPECT().RescheduleNotification(notifID, newTime, "Snoozed", gomock.Any()).Return(updated, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), "notification:record:"+notifID).Return(nil).Times(1)
 
 	ctx := setupTestContext()
+	cfg := config.New()
+	cfg.EnableEnv("")
+	cfg.SetDefault("ROUTING_KEY", "test.routing.key")
+
 	srv := &DelayedNotifierService{
-		repo:  repo,
-		redis: redisClient,
-		ctx:   ctx,
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
+		cfg:     cfg,
+	}
+
+	nf, err := srv.RescheduleNotification(notifID, &models.Notification{Time: newTime, Message: "Snoozed"})
+	require.NoError(t, err)
+	require.Equal(t, 1, nf.Version)
+	require.Equal(t, newTime, nf.Time)
+}
+
+func TestDelayedNotifierService_RescheduleNotificationValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+
+	cases := []struct {
+		name   string
+		id     string
+		req    *models.Notification
+		expErr string
+	}{
+		{
+			name:   "missing id",
+			id:     "",
+			req:    &models.Notification{Time: "2026-02-13T16:00:00+03:00"},
+			expErr: "invalid id",
+		},
+		{
+			name:   "missing time",
+			id:     "test-id",
+			req:    &models.Notification{Message: "Snoozed"},
+			expErr: "time is required",
+		},
+		{
+			name:   "invalid time",
+			id:     "test-id",
+			req:    &models.Notification{Time: "tomorrow"},
+			expErr: "invalid time format",
+		},
+		{
+			name:   "message too long",
+			id:     "test-id",
+			req:    &models.Notification{Time: "2026-02-13T16:00:00+03:00", Message: strings.Repeat("a", 4097)},
+			expErr: "at most 4096",
+		},
+	}
+	repo.EXPECT().GetNotification("test-id").Return(&models.Notification{Id: "test-id", Message: "Call", Status: "created", ChatId: 42, Channel: models.ChannelTelegram}, nil).Times(1)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: testSenders(),
+		ctx:     ctx,
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nf, err := srv.RescheduleNotification(tc.id, tc.req)
+			require.Error(t, err)
+			require.Nil(t, nf)
+			require.Contains(t, err.Error(), tc.expErr)
+			require.True(t, models.IsInvalid(err))
+		})
+	}
+}
+
+func TestDelayedNotifierService_GetNotificationFromCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notifID := "test-id-123"
+	cached := `{"id":"test-id-123","message":"Hi","status":"sent","attempts":2,"last_error":"timeout","sent_at":"2026-01-02T03:04:05Z"}`
+
+	redisClient.EXPECT().Get(gomock.Any(), "notification:record:"+notifID).Return(cached, nil).Times(1)
+	repo.EXPECT().GetNotification(gomock.Any()).Times(0)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
 	}
 
-	status, err := srv.GetNotificationStatus(notifID)
+	nf, err := srv.GetNotification(notifID)
 	require.NoError(t, err)
-	require.Equal(t, expectedStatus, status)
+	require.Equal(t, notifID, nf.Id)
+	require.Equal(t, models.StatusSent, nf.Status)
+	require.Equal(t, 2, nf.Attempts)
+	require.Equal(t, "timeout", nf.LastError)
+	require.NotNil(t, nf.SentAt)
 }
 
-func TestDelayedNotifierService_GetNotificationStatusFromDB(t *testing.T) {
+func TestDelayedNotifierService_GetNotificationFromDB(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -159,41 +435,101 @@ func TestDelayedNotifierService_GetNotificationStatusFromDB(t *testing.T) {
 	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
 
 	notifID := "test-id-123"
-	expectedStatus := "sent"
+	stored := &models.Notification{Id: notifID, Message: "Hi", Status: "failed", Attempts: 3, LastError: "chat not found"}
 
 	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("cache miss")).Times(1)
-	repo.EXPECT().GetNotificationStatus(notifID).Return(expectedStatus, nil).Times(1)
-	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), expectedStatus, gomock.Any()).Return(nil).Times(1)
+	repo.EXPECT().GetNotification(notifID).Return(stored, nil).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), "notification:record:"+notifID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, value any, ttl time.Duration) error {
+			var cached models.Notification
+			require.NoError(t, json.Unmarshal([]byte(value.(string)), &cached))
+			require.Equal(t, redis.CacheTTL, ttl)
+			require.Equal(t, models.StatusFailed, cached.Status)
+			require.Equal(t, "chat not found", cached.LastError)
+			return nil
+		}).Times(1)
 
 	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
+	}
+
+	nf, err := srv.GetNotification(notifID)
+	require.NoError(t, err)
+	require.Equal(t, stored, nf)
+}
+
+func TestDelayedNotifierService_GetNotificationCachesPendingBriefly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	stored := &models.Notification{Id: "test-id-123", Message: "Hi", Status: models.StatusCreated}
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("cache miss")).Times(1)
+	repo.EXPECT().GetNotification("test-id-123").Return(stored, nil).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), "notification:record:test-id-123", gomock.Any(), redis.PendingCacheTTL).Return(nil).Times(1)
+
 	srv := &DelayedNotifierService{
 		repo:  repo,
 		redis: redisClient,
-		ctx:   ctx,
+		ctx:   setupTestContext(),
 	}
 
-	status, err := srv.GetNotificationStatus(notifID)
+	_, err := srv.GetNotification("test-id-123")
 	require.NoError(t, err)
-	require.Equal(t, expectedStatus, status)
 }
 
-func TestDelayedNotifierService_GetNotificationStatusValidation(t *testing.T) {
+func TestDelayedNotifierService_GetNotificationIgnoresUnreadableCache(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
 	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
 
+	notifID := "test-id-123"
+	stored := &models.Notification{Id: notifID, Status: "created"}
+
+	// A status string cached by an older version of the service.
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("created", nil).Times(1)
+	repo.EXPECT().GetNotification(notifID).Return(stored, nil).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
 	ctx := setupTestContext()
 	srv := &DelayedNotifierService{
-		repo:  repo,
-		redis: redisClient,
-		ctx:   ctx,
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
+	}
+
+	nf, err := srv.GetNotification(notifID)
+	require.NoError(t, err)
+	require.Equal(t, stored, nf)
+}
+
+func TestDelayedNotifierService_GetNotificationValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
 	}
 
-	status, err := srv.GetNotificationStatus("")
+	nf, err := srv.GetNotification("")
 	require.Error(t, err)
-	require.Empty(t, status)
+	require.Nil(t, nf)
 	require.Contains(t, err.Error(), "invalid id")
 }
 
@@ -205,14 +541,16 @@ func TestDelayedNotifierService_DeleteNotificationSuccess(t *testing.T) {
 	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
 	notifID := "test-id-123"
 
-	repo.EXPECT().DeleteNotification(notifID).Return(nil).Times(1)
-	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	repo.EXPECT().CancelNotification(notifID).Return(nil).Times(1)
+	redisClient.EXPECT().SetWithExpiration(gomock.Any(), "notification:cancelled:"+notifID, "cancelled", gomock.Any()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), "notification:record:"+notifID).Return(nil).Times(1)
 
 	ctx := setupTestContext()
 	srv := &DelayedNotifierService{
-		repo:  repo,
-		redis: redisClient,
-		ctx:   ctx,
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
 	}
 
 	err := srv.DeleteNotification(notifID)
@@ -228,9 +566,10 @@ func TestDelayedNotifierService_DeleteNotificationFail(t *testing.T) {
 
 	ctx := setupTestContext()
 	srv := &DelayedNotifierService{
-		repo:  repo,
-		redis: redisClient,
-		ctx:   ctx,
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
 	}
 
 	err := srv.DeleteNotification("")
@@ -247,13 +586,14 @@ func TestDelayedNotifierService_DeleteNotificationRepoError(t *testing.T) {
 	notifID := "test-id-123"
 	expectedErr := errors.New("database error")
 
-	repo.EXPECT().DeleteNotification(notifID).Return(expectedErr).Times(1)
+	repo.EXPECT().CancelNotification(notifID).Return(expectedErr).Times(1)
 
 	ctx := setupTestContext()
 	srv := &DelayedNotifierService{
-		repo:  repo,
-		redis: redisClient,
-		ctx:   ctx,
+		repo:    repo,
+		redis:   redisClient,
+		senders: testSenders(),
+		ctx:     ctx,
 	}
 
 	err := srv.DeleteNotification(notifID)
@@ -261,7 +601,7 @@ func TestDelayedNotifierService_DeleteNotificationRepoError(t *testing.T) {
 	require.Equal(t, expectedErr, err)
 }
 
-func TestDelayedNotifierService_GetAllNotificationsSuccess(t *testing.T) {
+func TestDelayedNotifierService_ListNotificationsSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -283,38 +623,41 @@ func TestDelayedNotifierService_GetAllNotificationsSuccess(t *testing.T) {
 		},
 	}
 
-	repo.EXPECT().GetAllNotifications().Return(expectedNotifications, nil).Times(1)
+	repo.EXPECT().ListNotifications(&models.NotificationQuery{Desc: true, Limit: defaultListLimit}).Return(expectedNotifications, 2, nil).Times(1)
 
 	ctx := setupTestContext()
 	srv := &DelayedNotifierService{
-		repo: repo,
-		ctx:  ctx,
+		repo:    repo,
+		senders: testSenders(),
+		ctx:     ctx,
 	}
 
-	notifications, err := srv.GetAllNotifications()
+	page, err := srv.ListNotifications(&models.NotificationFilter{})
 	require.NoError(t, err)
-	require.Equal(t, expectedNotifications, notifications)
-	require.Len(t, notifications, 2)
+	require.Equal(t, expectedNotifications, page.Notifications)
+	require.Equal(t, 2, page.Total)
+	require.Empty(t, page.NextCursor)
 }
 
-func TestDelayedNotifierService_GetAllNotificationsError(t *testing.T) {
+func TestDelayedNotifierService_ListNotificationsError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
 	expectedErr := errors.New("database connection error")
 
-	repo.EXPECT().GetAllNotifications().Return(nil, expectedErr).Times(1)
+	repo.EXPECT().ListNotifications(gomock.Any()).Return(nil, 0, expectedErr).Times(1)
 
 	ctx := setupTestContext()
 	srv := &DelayedNotifierService{
-		repo: repo,
-		ctx:  ctx,
+		repo:    repo,
+		senders: testSenders(),
+		ctx:     ctx,
 	}
 
-	notifications, err := srv.GetAllNotifications()
+	page, err := srv.ListNotifications(&models.NotificationFilter{})
 	require.Error(t, err)
-	require.Nil(t, notifications)
+	require.Nil(t, page)
 	require.Equal(t, expectedErr, err)
 }
 
@@ -332,24 +675,55 @@ func TestDelayedNotifierService_ProcessNotificationSuccess(t *testing.T) {
 		ChatId:  123456789,
 	}
 
-	repo.EXPECT().UpdateNotificationStatus("test-id", "sending").Return(nil).Times(1)
-	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), "sending", gomock.Any()).Return(nil).Times(1)
-	telegramClient.EXPECT().SendMessage(int64(123456789), "Test message").Return(nil).Times(1)
-	repo.EXPECT().UpdateNotificationStatus("test-id", "sent").Return(nil).Times(1)
-	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), "sent", gomock.Any()).Return(nil).Times(1)
+	redisClient.EXPECT().Get(gomock.Any(), "notification:cancelled:test-id").Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	telegramClient.EXPECT().SendMessage(int64(123456789), "Test message", "", gomock.Nil()).Return(1, nil).Times(1)
+	repo.EXPECT().MarkNotificationSent("test-id", 0, "telegram", int64(123456789), []int{1}, gomock.Nil()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 	ctx := setupTestContext()
 	srv := &DelayedNotifierService{
-		repo:           repo,
-		telegramClient: telegramClient,
-		redis:          redisClient,
-		ctx:            ctx,
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:   redisClient,
+		ctx:     ctx,
 	}
 
 	err := srv.ProcessNotification(notification)
 	require.NoError(t, err)
 }
 
+func TestDelayedNotifierService_ProcessNotificationAttachmentOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:         "test-id",
+		ChatId:     123456789,
+		Attachment: &models.Attachment{Type: models.AttachmentLocation, Latitude: 55.75, Longitude: 37.62},
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), "notification:cancelled:test-id").Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	telegramClient.EXPECT().SendAttachment(int64(123456789), gomock.Any(), "", "", gomock.Nil()).Return(1, nil).Times(1)
+	repo.EXPECT().MarkNotificationSent("test-id", 0, "telegram", int64(123456789), []int{1}, gomock.Nil()).Return(nil).Times(1)
+
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:   redisClient,
+		ctx:     setupTestContext(),
+	}
+
+	require.NoError(t, srv.ProcessNotification(notification))
+}
+
 func TestDelayedNotifierService_ProcessNotificationTelegramError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -366,18 +740,19 @@ func TestDelayedNotifierService_ProcessNotificationTelegramError(t *testing.T) {
 
 	telegramErr := errors.New("telegram api error")
 
-	repo.EXPECT().UpdateNotificationStatus("test-id", "sending").Return(nil).Times(1)
-	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), "sending", gomock.Any()).Return(nil).Times(1)
-	telegramClient.EXPECT().SendMessage(int64(123456789), "Test message").Return(telegramErr).Times(1)
-	repo.EXPECT().UpdateNotificationStatus("test-id", "failed").Return(nil).Times(1)
-	redisClient.EXPECT().SetWithExpiration(gomock.Any(), gomock.Any(), "failed", gomock.Any()).Return(nil).Times(1)
+	redisClient.EXPECT().Get(gomock.Any(), "notification:cancelled:test-id").Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	telegramClient.EXPECT().SendMessage(int64(123456789), "Test message", "", gomock.Nil()).Return(0, telegramErr).Times(1)
+	repo.EXPECT().MarkNotificationFailed("test-id", 0, gomock.Not(""), gomock.Nil()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 	ctx := setupTestContext()
 	srv := &DelayedNotifierService{
-		repo:           repo,
-		telegramClient: telegramClient,
-		redis:          redisClient,
-		ctx:            ctx,
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:   redisClient,
+		ctx:     ctx,
 	}
 
 	err := srv.ProcessNotification(notification)
@@ -385,6 +760,449 @@ func TestDelayedNotifierService_ProcessNotificationTelegramError(t *testing.T) {
 	require.Contains(t, err.Error(), "failed to send telegram message")
 }
 
+func TestDelayedNotifierService_ProcessNotificationSchedulesRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:       "test-id",
+		Message:  "Test message",
+		Time:     time.Now().Format(time.RFC3339),
+		ChatId:   123456789,
+		Attempts: 1,
+		Version:  2,
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 2, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	telegramClient.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, errors.New("internal server error")).Times(1)
+	repo.EXPECT().ScheduleRetry("test-id", 2, "internal server error", gomock.Any()).
+		DoAndReturn(func(_ string, _ int, _ string, outbox *models.OutboxMessage) (*models.Notification, error) {
+			delay := time.Until(outbox.SendAt)
+			require.InDelta(t, (20 * time.Second).Seconds(), delay.Seconds(), 1)
+			return &models.Notification{Id: "test-id", Version: 3, Attempts: 2}, nil
+		}).Times(1)
+	repo.EXPECT().MarkNotificationFailed(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:        repo,
+		senders:     NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:       redisClient,
+		ctx:         ctx,
+		cfg:         config.New(),
+		retryPolicy: RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Second, MaxAge: time.Hour},
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.NoError(t, err)
+}
+
+func TestDelayedNotifierService_ProcessNotificationPermanentErrorSkipsRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:      "test-id",
+		Message: "Test message",
+		ChatId:  123456789,
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	telegramClient.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, Permanent(errors.New("chat not found"))).Times(1)
+	repo.EXPECT().ScheduleRetry(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	repo.EXPECT().MarkNotificationFailed("test-id", 0, "chat not found", gomock.Nil()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:        repo,
+		senders:     NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:       redisClient,
+		ctx:         ctx,
+		retryPolicy: RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Second, MaxAge: time.Hour},
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "chat not found")
+}
+
+func TestDelayedNotifierService_ProcessNotificationSavesDeliveredParts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:      "test-id",
+		Message: strings.Repeat("a", 4000) + "\n\n" + strings.Repeat("b", 4000),
+		ChatId:  123456789,
+		Split:   true,
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	gomock.InOrder(
+		telegramClient.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(7, nil),
+		telegramClient.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, errors.New("timeout")),
+	)
+	repo.EXPECT().SaveMessageIds("test-id", 0, []int{7}).Return(nil).Times(1)
+	repo.EXPECT().MarkNotificationFailed("test-id", 0, gomock.Not(""), gomock.Nil()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:   redisClient,
+		ctx:     ctx,
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.Error(t, err)
+}
+
+func TestDelayedNotifierService_ProcessNotificationFallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	emailClient := servicemocks.NewMockEmailClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:      "test-id",
+		Message: "Test message",
+		Subject: "On-call",
+		ChatId:  123456789,
+		Fallback: []models.FallbackTarget{
+			{Channel: models.ChannelEmail, Recipient: "oncall@example.com"},
+		},
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	telegramClient.EXPECT().SendMessage(int64(123456789), "Test message", "", gomock.Nil()).Return(0, Permanent(errors.New("bot was blocked by the user"))).Times(1)
+	emailClient.EXPECT().SendEmail("oncall@example.com", "On-call", "Test message").Return(nil).Times(1)
+	repo.EXPECT().MarkNotificationSent("test-id", 0, "email", int64(0), []int(nil), gomock.Nil()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil), NewEmailSender(emailClient)),
+		redis:   redisClient,
+		ctx:     ctx,
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.NoError(t, err)
+	require.Equal(t, "email", notification.DeliveredVia)
+}
+
+func TestDelayedNotifierService_ProcessNotificationFallbackChat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:       "test-id",
+		Message:  "Test message",
+		ChatId:   123456789,
+		Fallback: []models.FallbackTarget{{Channel: models.ChannelTelegram, ChatId: 777}},
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	telegramClient.EXPECT().SendMessage(int64(123456789), "Test message", "", gomock.Nil()).Return(0, Permanent(errors.New("bot was blocked by the user"))).Times(1)
+	telegramClient.EXPECT().SendMessage(int64(777), "Test message", "", gomock.Nil()).Return(5, nil).Times(1)
+	repo.EXPECT().MarkNotificationSent("test-id", 0, "telegram", int64(777), []int{5}, gomock.Nil()).Return(nil).Times(1)
+
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:   redisClient,
+		ctx:     setupTestContext(),
+	}
+
+	require.NoError(t, srv.ProcessNotification(notification))
+	require.Equal(t, int64(777), notification.DeliveredChatId)
+}
+
+func TestDelayedNotifierService_ProcessNotificationNoFallbackOnTransientError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	emailClient := servicemocks.NewMockEmailClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:      "test-id",
+		Message: "Test message",
+		ChatId:  123456789,
+		Fallback: []models.FallbackTarget{
+			{Channel: models.ChannelEmail, Recipient: "oncall@example.com"},
+		},
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	telegramClient.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, errors.New("connection reset")).Times(1)
+	emailClient.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	repo.EXPECT().MarkNotificationFailed("test-id", 0, gomock.Not(""), gomock.Nil()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil), NewEmailSender(emailClient)),
+		redis:   redisClient,
+		ctx:     ctx,
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "connection reset")
+}
+
+func TestDelayedNotifierService_ProcessNotificationEmailSuccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	emailClient := servicemocks.NewMockEmailClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:        "test-id",
+		Message:   "Test body",
+		Channel:   models.ChannelEmail,
+		Recipient: "user@example.com",
+		Subject:   "Test subject",
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), "notification:cancelled:test-id").Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	emailClient.EXPECT().SendEmail("user@example.com", "Test subject", "Test body").Return(nil).Times(1)
+	telegramClient.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	repo.EXPECT().MarkNotificationSent("test-id", 0, "email", int64(0), []int(nil), gomock.Nil()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil), NewEmailSender(emailClient)),
+		redis:   redisClient,
+		ctx:     ctx,
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.NoError(t, err)
+}
+
+func TestDelayedNotifierService_ProcessNotificationWebhookPayload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	webhookClient := servicemocks.NewMockWebhookClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:        "test-id",
+		Channel:   models.ChannelWebhook,
+		Recipient: "https://example.com/hook",
+		Headers:   map[string]string{"X-Tenant": "acme"},
+		Payload:   []byte(`{"event":"reminder"}`),
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), "notification:cancelled:test-id").Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	webhookClient.EXPECT().SendWebhook("https://example.com/hook", map[string]string{"X-Tenant": "acme"}, []byte(`{"event":"reminder"}`)).Return(nil).Times(1)
+	repo.EXPECT().MarkNotificationSent("test-id", 0, "webhook", int64(0), []int(nil), gomock.Nil()).Return(nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewWebhookSender(webhookClient)),
+		redis:   redisClient,
+		ctx:     ctx,
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.NoError(t, err)
+}
+
+func TestDelayedNotifierService_ProcessNotificationWebhookDefaultBody(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	webhookClient := servicemocks.NewMockWebhookClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:        "test-id",
+		Message:   "Test message",
+		Time:      "2026-01-01T10:00:00Z",
+		Channel:   models.ChannelWebhook,
+		Recipient: "https://example.com/hook",
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	webhookClient.EXPECT().SendWebhook("https://example.com/hook", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, _ map[string]string, payload []byte) error {
+			require.JSONEq(t, `{"id":"test-id","message":"Test message","time":"2026-01-01T10:00:00Z"}`, string(payload))
+			return nil
+		}).Times(1)
+	repo.EXPECT().MarkNotificationSent("test-id", 0, "webhook", int64(0), []int(nil), gomock.Nil()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewWebhookSender(webhookClient)),
+		redis:   redisClient,
+		ctx:     ctx,
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.NoError(t, err)
+}
+
+func TestDelayedNotifierService_ProcessNotificationCancelledTombstone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:      "test-id",
+		Message: "Test message",
+		ChatId:  123456789,
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), "notification:cancelled:test-id").Return("cancelled", nil).Times(1)
+	telegramClient.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:   redisClient,
+		ctx:     ctx,
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.NoError(t, err)
+}
+
+func TestDelayedNotifierService_ProcessNotificationCancelledInDB(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:      "test-id",
+		Message: "Test message",
+		ChatId:  123456789,
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), "notification:cancelled:test-id").Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(false, nil).Times(1)
+	telegramClient.EXPECT().SendMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	ctx := setupTestContext()
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:   redisClient,
+		ctx:     ctx,
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.NoError(t, err)
+}
+
+func TestDelayedNotifierService_ProcessNotificationRecurringSchedulesNext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockNotificationRepositoryInterface(ctrl)
+	telegramClient := servicemocks.NewMockTelegramClientInterface(ctrl)
+	redisClient := servicemocks.NewMockRedisClientInterface(ctrl)
+
+	notification := &models.Notification{
+		Id:       "test-id",
+		Message:  "Daily standup",
+		ChatId:   123456789,
+		Schedule: "every 1h",
+		Time:     time.Now().Format(time.RFC3339),
+	}
+
+	redisClient.EXPECT().Get(gomock.Any(), "notification:cancelled:test-id").Return("", errors.New("redis: nil")).Times(1)
+	repo.EXPECT().MarkNotificationSending("test-id", 0, gomock.Any()).Return(true, nil).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	telegramClient.EXPECT().SendMessage(int64(123456789), "Daily standup", "", gomock.Nil()).Return(1, nil).Times(1)
+	repo.EXPECT().MarkNotificationSent("test-id", 0, "telegram", int64(123456789), []int{1}, gomock.Any()).DoAndReturn(
+		func(_ string, _ int, _ string, _ int64, _ []int, occurrence *models.Occurrence) error {
+			require.NotNil(t, occurrence)
+			require.Equal(t, "test.routing.key", occurrence.Outbox.RoutingKey)
+			require.WithinDuration(t, time.Now().Add(time.Hour), occurrence.Outbox.SendAt, time.Minute)
+			require.Equal(t, occurrence.Outbox.SendAt.Format(time.RFC3339), occurrence.NextTime)
+			return nil
+		}).Times(1)
+	redisClient.EXPECT().Del(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	ctx := setupTestContext()
+	cfg := config.New()
+	cfg.EnableEnv("")
+	cfg.SetDefault("ROUTING_KEY", "test.routing.key")
+
+	srv := &DelayedNotifierService{
+		repo:    repo,
+		senders: NewSenderRegistry(NewTelegramSender(telegramClient, nil, nil)),
+		redis:   redisClient,
+		ctx:     ctx,
+		cfg:     cfg,
+	}
+
+	err := srv.ProcessNotification(notification)
+	require.NoError(t, err)
+}
+
 func TestDelayedNotifierService_ProcessNotificationValidation(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -414,6 +1232,33 @@ func TestDelayedNotifierService_ProcessNotificationValidation(t *testing.T) {
 			},
 			expErr: "missing required fields",
 		},
+		{
+			name: "email without recipient",
+			notification: &models.Notification{
+				Id:      "test-id",
+				Message: "Test message",
+				Channel: models.ChannelEmail,
+			},
+			expErr: "recipient is required",
+		},
+		{
+			name: "webhook without url",
+			notification: &models.Notification{
+				Id:      "test-id",
+				Message: "Test message",
+				Channel: models.ChannelWebhook,
+			},
+			expErr: "recipient is required",
+		},
+		{
+			name: "unsupported channel",
+			notification: &models.Notification{
+				Id:      "test-id",
+				Message: "Test message",
+				Channel: "pigeon",
+			},
+			expErr: "unsupported channel",
+		},
 		{
 			name: "missing chat_id",
 			notification: &models.Notification{
@@ -421,14 +1266,15 @@ func TestDelayedNotifierService_ProcessNotificationValidation(t *testing.T) {
 				Message: "Test message",
 				ChatId:  0,
 			},
-			expErr: "missing required fields",
+			expErr: "chat_id is required",
 		},
 	}
 
 	ctx := setupTestContext()
 	srv := &DelayedNotifierService{
-		repo: repo,
-		ctx:  ctx,
+		repo:    repo,
+		senders: testSenders(),
+		ctx:     ctx,
 	}
 
 	for _, tc := range cases {
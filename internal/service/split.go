@@ -0,0 +1,46 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+var splitSeparators = []string{"\n\n", "\n", " "}
+
+// splitMessage breaks text into parts of at most limit characters, preferring
+// paragraph boundaries, then line breaks, then spaces. A single word longer
+// than limit is cut mid-word.
+func splitMessage(text string, limit int) []string {
+	var parts []string
+	for utf8.RuneCountInString(text) > limit {
+		cut := splitPoint(text, limit)
+		if part := strings.TrimRight(text[:cut], " \n"); part != "" {
+			parts = append(parts, part)
+		}
+		text = strings.TrimLeft(text[cut:], " \n")
+	}
+	if text = strings.TrimRight(text, " \n"); text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+// splitPoint returns the byte offset at which the first part of text ends.
+func splitPoint(text string, limit int) int {
+	end, n := len(text), 0
+	for i := range text {
+		if n == limit {
+			end = i
+			break
+		}
+		n++
+	}
+
+	head := text[:end]
+	for _, sep := range splitSeparators {
+		if i := strings.LastIndex(head, sep); i > 0 {
+			return i + len(sep)
+		}
+	}
+	return end
+}
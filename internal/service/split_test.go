@@ -0,0 +1,66 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitMessage(t *testing.T) {
+	cases := []struct {
+		name  string
+		text  string
+		limit int
+		parts []string
+	}{
+		{
+			name:  "fits",
+			text:  "short",
+			limit: 10,
+			parts: []string{"short"},
+		},
+		{
+			name:  "paragraph boundary",
+			text:  "first para\nline\n\nsecond para",
+			limit: 20,
+			parts: []string{"first para\nline", "second para"},
+		},
+		{
+			name:  "line boundary",
+			text:  "line one\nline two\nline three",
+			limit: 18,
+			parts: []string{"line one\nline two", "line three"},
+		},
+		{
+			name:  "word boundary",
+			text:  "alpha beta gamma",
+			limit: 11,
+			parts: []string{"alpha beta", "gamma"},
+		},
+		{
+			name:  "long word is cut",
+			text:  "абвгдеёжзи",
+			limit: 4,
+			parts: []string{"абвг", "деёж", "зи"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.parts, splitMessage(tc.text, tc.limit))
+		})
+	}
+}
+
+func TestSplitMessageRespectsLimit(t *testing.T) {
+	text := strings.Repeat("Отчет по заказам за неделю.\n", 400)
+	parts := splitMessage(text, telegramMaxMessageLength)
+
+	require.Greater(t, len(parts), 1)
+	for _, part := range parts {
+		require.LessOrEqual(t, utf8.RuneCountInString(part), telegramMaxMessageLength)
+	}
+	require.Equal(t, strings.TrimSpace(text), strings.Join(parts, "\n"))
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wb-go/wbf/config"
+)
+
+const (
+	defaultDir        = "./data/attachments"
+	defaultMaxSizeMB  = 50
+	maxExtensionChars = 10
+)
+
+// ErrTooLarge is returned by Save when the file exceeds the configured
+// maximum size.
+var ErrTooLarge = errors.New("attachment is too large")
+
+// LocalStorage keeps uploaded attachments as files in a directory. Every
+// replica that sends notifications must see the same directory, e.g. through
+// a shared volume; otherwise use an object store implementing the same
+// methods.
+type LocalStorage struct {
+	dir     string
+	maxSize int64
+}
+
+func NewLocalStorage(cfg *config.Config) (*LocalStorage, error) {
+	dir := cfg.GetString("ATTACHMENTS_DIR")
+	if dir == "" {
+		dir = defaultDir
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create attachments dir: %w", err)
+	}
+	return &LocalStorage{dir: dir, maxSize: MaxSize(cfg)}, nil
+}
+
+// MaxSize returns the largest attachment in bytes, ATTACHMENT_MAX_SIZE_MB.
+func MaxSize(cfg *config.Config) int64 {
+	maxSizeMB := cfg.GetInt("ATTACHMENT_MAX_SIZE_MB")
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSizeMB
+	}
+	return int64(maxSizeMB) << 20
+}
+
+// Save stores the content of r under a new random key, keeping the extension
+// of name.
+func (s *LocalStorage) Save(name string, r io.Reader) (string, error) {
+	key := uuid.New().String() + extension(name)
+	path := filepath.Join(s.dir, key)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create attachment file: %w", err)
+	}
+	n, err := io.Copy(f, io.LimitReader(r, s.maxSize+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > s.maxSize {
+		err = fmt.Errorf("%w: limit is %d MB", ErrTooLarge, s.maxSize>>20)
+	}
+	if err != nil {
+		os.Remove(path)
+		if errors.Is(err, ErrTooLarge) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to save attachment: %w", err)
+	}
+	return key, nil
+}
+
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	return nil
+}
+
+// path resolves key inside the storage directory, refusing keys that could
+// point anywhere else.
+func (s *LocalStorage) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("invalid attachment key: %q", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+func extension(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if len(ext) < 2 || len(ext) > maxExtensionChars {
+		return ""
+	}
+	for _, c := range ext[1:] {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return ""
+		}
+	}
+	return ext
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	s := &LocalStorage{dir: t.TempDir(), maxSize: 16}
+
+	key, err := s.Save("Report Q1.PDF", strings.NewReader("%PDF-1.7"))
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(key, ".pdf"))
+
+	f, err := s.Open(key)
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Equal(t, "%PDF-1.7", string(data))
+
+	require.NoError(t, s.Delete(key))
+	_, err = s.Open(key)
+	require.Error(t, err)
+	require.NoError(t, s.Delete(key), "deleting a missing file is not an error")
+
+	_, err = s.Save("big.bin", strings.NewReader(strings.Repeat("x", 17)))
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	for _, key := range []string{"", "../secret", "/etc/passwd", ".hidden", "a/b"} {
+		_, err := s.Open(key)
+		require.ErrorContains(t, err, "invalid attachment key", key)
+	}
+}
+
+func TestExtension(t *testing.T) {
+	require.Equal(t, ".pdf", extension("report.PDF"))
+	require.Equal(t, ".jpeg", extension("photo.jpeg"))
+	require.Equal(t, "", extension("noext"))
+	require.Equal(t, "", extension("weird.p$f"))
+	require.Equal(t, "", extension("long.abcdefghijk"))
+}
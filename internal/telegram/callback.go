@@ -0,0 +1,52 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxCallbackDataLength is the limit Telegram puts on callback_data.
+const maxCallbackDataLength = 64
+
+// Button is an inline keyboard button: a link when URL is set, otherwise a
+// callback button sending Data back to the bot when pressed.
+type Button struct {
+	Text string
+	URL  string
+	Data string
+}
+
+// Callback is a pressed callback button, decoded from its data and the
+// message it was attached to.
+type Callback struct {
+	Action         string
+	NotificationId string
+	Arg            string
+	ChatId         int64
+	MessageId      int
+}
+
+// EncodeCallback packs an action on a notification into callback data. arg is
+// optional, e.g. the delay of a snooze.
+func EncodeCallback(action, notificationId, arg string) (string, error) {
+	data := action + ":" + notificationId
+	if arg != "" {
+		data += ":" + arg
+	}
+	if len(data) > maxCallbackDataLength {
+		return "", fmt.Errorf("callback data %q exceeds %d bytes", data, maxCallbackDataLength)
+	}
+	return data, nil
+}
+
+func DecodeCallback(data string) (Callback, error) {
+	parts := strings.SplitN(data, ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return Callback{}, fmt.Errorf("invalid callback data %q", data)
+	}
+	cb := Callback{Action: parts[0], NotificationId: parts[1]}
+	if len(parts) == 3 {
+		cb.Arg = parts[2]
+	}
+	return cb, nil
+}
@@ -3,12 +3,32 @@ package telegram
 import (
 	"DelayedNotifier/pkg/logger"
 	"context"
+	"fmt"
+	"io"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/wb-go/wbf/config"
 	"go.uber.org/zap"
 )
 
+// Attachment types; the values match models.Attachment.Type.
+const (
+	AttachmentPhoto    = "photo"
+	AttachmentDocument = "document"
+	AttachmentLocation = "location"
+)
+
+// Attachment is a file or a location to send. Files are uploaded from Reader
+// when it is set, otherwise Telegram fetches them from URL.
+type Attachment struct {
+	Type      string
+	URL       string
+	FileName  string
+	Reader    io.Reader
+	Latitude  float64
+	Longitude float64
+}
+
 type Client struct {
 	bot *tgbotapi.BotAPI
 	cfg *config.Config
@@ -31,16 +51,212 @@ func NewClient(cfg *config.Config, ctx context.Context) (*Client, error) {
 	}, nil
 }
 
-func (c *Client) SendMessage(chatId int64, message string) error {
+// SendMessage sends message to chatId. parseMode is empty for plain text or
+// one of ParseModeMarkdownV2 and ParseModeHTML; keyboard may be nil.
+func (c *Client) SendMessage(chatId int64, message, parseMode string, keyboard [][]Button) (int, error) {
 	msg := tgbotapi.NewMessage(chatId, message)
+	msg.ParseMode = parseMode
+	if len(keyboard) > 0 {
+		msg.ReplyMarkup = inlineKeyboard(keyboard)
+	}
 
-	_, err := c.bot.Send(msg)
+	sent, err := c.bot.Send(msg)
 	if err != nil {
-		return err
+		return 0, classifyError(err)
 	}
 
 	logger.GetLoggerFromCtx(c.ctx).Info("Telegram message sent successfully",
-		zap.Int64("chat_id", chatId))
+		zap.Int64("chat_id", chatId),
+		zap.Int("message_id", sent.MessageID))
+
+	return sent.MessageID, nil
+}
+
+// SendAttachment sends a photo, document or location to chatId. caption is
+// formatted with parseMode and ignored for locations; keyboard may be nil.
+func (c *Client) SendAttachment(chatId int64, attachment Attachment, caption, parseMode string, keyboard [][]Button) (int, error) {
+	var markup any
+	if len(keyboard) > 0 {
+		markup = inlineKeyboard(keyboard)
+	}
+
+	var msg tgbotapi.Chattable
+	switch attachment.Type {
+	case AttachmentPhoto:
+		photo := tgbotapi.NewPhoto(chatId, attachmentFile(attachment))
+		photo.Caption = caption
+		photo.ParseMode = parseMode
+		photo.ReplyMarkup = markup
+		msg = photo
+	case AttachmentDocument:
+		document := tgbotapi.NewDocument(chatId, attachmentFile(attachment))
+		document.Caption = caption
+		document.ParseMode = parseMode
+		document.ReplyMarkup = markup
+		msg = document
+	case AttachmentLocation:
+		location := tgbotapi.NewLocation(chatId, attachment.Latitude, attachment.Longitude)
+		location.ReplyMarkup = markup
+		msg = location
+	default:
+		err := fmt.Errorf("unsupported attachment type: %s", attachment.Type)
+		return 0, &SendError{Message: err.Error(), Err: err}
+	}
+
+	sent, err := c.bot.Send(msg)
+	if err != nil {
+		return 0, classifyError(err)
+	}
+
+	logger.GetLoggerFromCtx(c.ctx).Info("Telegram attachment sent successfully",
+		zap.Int64("chat_id", chatId),
+		zap.String("type", attachment.Type),
+		zap.Int("message_id", sent.MessageID))
 
+	return sent.MessageID, nil
+}
+
+func attachmentFile(attachment Attachment) tgbotapi.RequestFileData {
+	if attachment.Reader != nil {
+		return tgbotapi.FileReader{Name: attachment.FileName, Reader: attachment.Reader}
+	}
+	return tgbotapi.FileURL(attachment.URL)
+}
+
+func (c *Client) AnswerCallback(callbackId, text string) error {
+	if _, err := c.bot.Request(tgbotapi.NewCallback(callbackId, text)); err != nil {
+		return classifyError(err)
+	}
+	return nil
+}
+
+// RemoveKeyboard strips the inline keyboard from a sent message so its
+// buttons cannot be pressed twice.
+func (c *Client) RemoveKeyboard(chatId int64, messageId int) error {
+	empty := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if _, err := c.bot.Request(tgbotapi.NewEditMessageReplyMarkup(chatId, messageId, empty)); err != nil {
+		return classifyError(err)
+	}
 	return nil
 }
+
+// EditMessageText replaces the text of a sent message. The inline keyboard is
+// replaced by keyboard, so pass the current one to keep it.
+func (c *Client) EditMessageText(chatId int64, messageId int, text, parseMode string, keyboard [][]Button) error {
+	edit := tgbotapi.NewEditMessageText(chatId, messageId, text)
+	edit.ParseMode = parseMode
+	if len(keyboard) > 0 {
+		markup := inlineKeyboard(keyboard)
+		edit.ReplyMarkup = &markup
+	}
+	return c.edit(edit, chatId, messageId)
+}
+
+// EditMessageCaption replaces the caption of a sent photo or document, with
+// the keyboard handled as in EditMessageText.
+func (c *Client) EditMessageCaption(chatId int64, messageId int, caption, parseMode string, keyboard [][]Button) error {
+	edit := tgbotapi.NewEditMessageCaption(chatId, messageId, caption)
+	edit.ParseMode = parseMode
+	if len(keyboard) > 0 {
+		markup := inlineKeyboard(keyboard)
+		edit.ReplyMarkup = &markup
+	}
+	return c.edit(edit, chatId, messageId)
+}
+
+func (c *Client) edit(edit tgbotapi.Chattable, chatId int64, messageId int) error {
+	// Repeating an edit is not an error.
+	if _, err := c.bot.Request(edit); err != nil && !hasDescription(err, "message is not modified") {
+		return classifyError(err)
+	}
+
+	logger.GetLoggerFromCtx(c.ctx).Info("Telegram message edited",
+		zap.Int64("chat_id", chatId),
+		zap.Int("message_id", messageId))
+	return nil
+}
+
+// DeleteMessage deletes a sent message. A message that is already gone
+// counts as deleted.
+func (c *Client) DeleteMessage(chatId int64, messageId int) error {
+	if _, err := c.bot.Request(tgbotapi.NewDeleteMessage(chatId, messageId)); err != nil && !hasDescription(err, "message to delete not found") {
+		return classifyError(err)
+	}
+
+	logger.GetLoggerFromCtx(c.ctx).Info("Telegram message deleted",
+		zap.Int64("chat_id", chatId),
+		zap.Int("message_id", messageId))
+	return nil
+}
+
+// PollUpdates receives updates by long polling and passes them to handle
+// until ctx is done.
+func (c *Client) PollUpdates(ctx context.Context, allowed []string, handle func(tgbotapi.Update)) {
+	// Telegram refuses getUpdates while a webhook is set, e.g. after
+	// switching back from webhook mode.
+	if info, err := c.bot.GetWebhookInfo(); err == nil && info.URL != "" {
+		logger.GetLoggerFromCtx(c.ctx).Warn("Deleting Telegram webhook to poll for updates",
+			zap.String("url", info.URL))
+		if err := c.DeleteWebhook(); err != nil {
+			logger.GetLoggerFromCtx(c.ctx).Error("Failed to delete Telegram webhook", zap.Error(err))
+		}
+	}
+
+	config := tgbotapi.NewUpdate(0)
+	config.Timeout = 30
+	config.AllowedUpdates = allowed
+
+	updates := c.bot.GetUpdatesChan(config)
+	defer c.bot.StopReceivingUpdates()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			handle(update)
+		}
+	}
+}
+
+// SetWebhook asks Telegram to push updates of the allowed types to url,
+// sending secret in the X-Telegram-Bot-Api-Secret-Token header.
+func (c *Client) SetWebhook(url, secret string, allowed []string) error {
+	params := tgbotapi.Params{"url": url}
+	params.AddNonEmpty("secret_token", secret)
+	if err := params.AddInterface("allowed_updates", allowed); err != nil {
+		return err
+	}
+	if _, err := c.bot.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set telegram webhook: %w", err)
+	}
+
+	logger.GetLoggerFromCtx(c.ctx).Info("Telegram webhook set", zap.String("url", url))
+	return nil
+}
+
+func (c *Client) DeleteWebhook() error {
+	if _, err := c.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("failed to delete telegram webhook: %w", err)
+	}
+	return nil
+}
+
+func inlineKeyboard(keyboard [][]Button) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(keyboard))
+	for _, buttons := range keyboard {
+		row := make([]tgbotapi.InlineKeyboardButton, 0, len(buttons))
+		for _, b := range buttons {
+			if b.URL != "" {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonURL(b.Text, b.URL))
+			} else {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonData(b.Text, b.Data))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
@@ -0,0 +1,77 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SendError is returned by Client for every failed request. Retryable tells
+// whether the same request may succeed later: flood control, Telegram
+// outages and network failures are retryable, while a blocked bot, an
+// unknown chat or a malformed message are not.
+type SendError struct {
+	Code       int
+	Message    string
+	RetryAfter time.Duration
+	Err        error
+	retryable  bool
+}
+
+func (e *SendError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("telegram api error %d: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("telegram request failed: %v", e.Err)
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SendError) Retryable() bool {
+	return e.retryable
+}
+
+// RetryDelay is the minimum wait Telegram asked for before the next attempt.
+func (e *SendError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		// Timeouts, refused connections and undecodable responses: Telegram
+		// was not reached or did not answer properly, so try again later.
+		return &SendError{Message: err.Error(), Err: err, retryable: true}
+	}
+
+	sendErr := &SendError{
+		Code:    apiErr.Code,
+		Message: apiErr.Message,
+		Err:     err,
+	}
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests:
+		sendErr.retryable = true
+		sendErr.RetryAfter = time.Duration(apiErr.RetryAfter) * time.Second
+	case apiErr.Code >= http.StatusInternalServerError:
+		sendErr.retryable = true
+	}
+	return sendErr
+}
+
+// hasDescription tells whether err is a Telegram API error whose description
+// contains text.
+func hasDescription(err error, text string) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, text)
+}
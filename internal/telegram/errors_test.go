@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{
+			name:       "flood control",
+			err:        &tgbotapi.Error{Code: 429, Message: "Too Many Requests: retry after 17", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 17}},
+			retryable:  true,
+			retryAfter: 17 * time.Second,
+		},
+		{
+			name:      "telegram outage",
+			err:       &tgbotapi.Error{Code: 502, Message: "Bad Gateway"},
+			retryable: true,
+		},
+		{
+			name:      "network timeout",
+			err:       &url.Error{Op: "Post", URL: "https://api.telegram.org", Err: context.DeadlineExceeded},
+			retryable: true,
+		},
+		{
+			name: "bot blocked",
+			err:  &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"},
+		},
+		{
+			name: "chat not found",
+			err:  &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"},
+		},
+		{
+			name: "parse error",
+			err:  &tgbotapi.Error{Code: 400, Message: "Bad Request: can't parse entities"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := classifyError(tc.err)
+
+			var sendErr *SendError
+			require.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &sendErr))
+			require.Equal(t, tc.retryable, sendErr.Retryable())
+			require.Equal(t, tc.retryAfter, sendErr.RetryDelay())
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	require.NoError(t, classifyError(nil))
+}
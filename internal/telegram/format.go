@@ -0,0 +1,256 @@
+package telegram
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	ParseModeMarkdownV2 = tgbotapi.ModeMarkdownV2
+	ParseModeHTML       = tgbotapi.ModeHTML
+)
+
+// markdownV2Reserved are the characters MarkdownV2 requires to be escaped
+// with a backslash outside of entities.
+const markdownV2Reserved = "_*[]()~`>#+-=|{}.!"
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// htmlTags are the tags Telegram accepts in HTML parse mode, with an attribute
+// each of them cannot do without.
+var htmlTags = map[string]string{
+	"b":          "",
+	"strong":     "",
+	"i":          "",
+	"em":         "",
+	"u":          "",
+	"ins":        "",
+	"s":          "",
+	"strike":     "",
+	"del":        "",
+	"tg-spoiler": "",
+	"code":       "",
+	"pre":        "",
+	"blockquote": "",
+	"span":       `class="tg-spoiler"`,
+	"a":          "href=",
+	"tg-emoji":   "emoji-id=",
+}
+
+// EscapeMarkdownV2 escapes text so it is shown literally in a MarkdownV2
+// message.
+func EscapeMarkdownV2(text string) string {
+	var b strings.Builder
+	for _, c := range text {
+		if c == '\\' || strings.ContainsRune(markdownV2Reserved, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// EscapeHTML escapes text so it is shown literally in an HTML message.
+func EscapeHTML(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
+// ValidateMarkdownV2 reports the first construct Telegram would refuse to
+// parse: an unescaped reserved character, an unclosed or overlapping entity,
+// or a link without a URL. Positions are in characters.
+func ValidateMarkdownV2(text string) error {
+	runes := []rune(text)
+	var open []string
+
+	toggle := func(delim string, pos int) error {
+		if n := len(open); n > 0 && open[n-1] == delim {
+			open = open[:n-1]
+			return nil
+		}
+		if slices.Contains(open, delim) {
+			return fmt.Errorf("entity %q at position %d overlaps another entity", delim, pos)
+		}
+		open = append(open, delim)
+		return nil
+	}
+
+	lineStart := true
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		atLineStart := lineStart
+		lineStart = c == '\n'
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case c == '\\':
+			if next < 1 || next > 126 {
+				return fmt.Errorf("invalid escape at position %d", i)
+			}
+			i++
+		case c == '`':
+			delim := "`"
+			if strings.HasPrefix(string(runes[i:min(i+3, len(runes))]), "```") {
+				delim = "```"
+			}
+			end, err := closeMarkdownV2Code(runes, i+len(delim), delim)
+			if err != nil {
+				return err
+			}
+			if end < 0 {
+				return fmt.Errorf("unclosed %q entity at position %d", delim, i)
+			}
+			i = end + len(delim) - 1
+		case c == '[':
+			open = append(open, "[")
+		case c == '!' && next == '[':
+			// Custom emoji: ![👍](tg://emoji?id=...)
+			open = append(open, "[")
+			i++
+		case c == ']':
+			if n := len(open); n == 0 || open[n-1] != "[" {
+				return fmt.Errorf("unexpected ']' at position %d", i)
+			}
+			open = open[:len(open)-1]
+			if next != '(' {
+				return fmt.Errorf("link at position %d has no url", i)
+			}
+			end := closeMarkdownV2URL(runes, i+2)
+			if end < 0 {
+				return fmt.Errorf("unclosed link url at position %d", i+1)
+			}
+			i = end
+		case c == '>' && atLineStart:
+			// Block quotation.
+		case c == '|' && next == '|':
+			if err := toggle("||", i); err != nil {
+				return err
+			}
+			i++
+		case c == '_' && next == '_':
+			if err := toggle("__", i); err != nil {
+				return err
+			}
+			i++
+		case c == '*' || c == '_' || c == '~':
+			if err := toggle(string(c), i); err != nil {
+				return err
+			}
+		case strings.ContainsRune(markdownV2Reserved, c):
+			return fmt.Errorf("character %q at position %d must be escaped with '\\'", c, i)
+		}
+	}
+
+	if len(open) > 0 {
+		return fmt.Errorf("unclosed %q entity", open[len(open)-1])
+	}
+	return nil
+}
+
+// closeMarkdownV2Code returns the index of the delimiter closing a code or
+// pre entity, or -1. Backticks inside must be escaped.
+func closeMarkdownV2Code(runes []rune, from int, delim string) (int, error) {
+	for i := from; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '`':
+			if strings.HasPrefix(string(runes[i:min(i+len(delim), len(runes))]), delim) {
+				return i, nil
+			}
+			return 0, fmt.Errorf("character '`' at position %d must be escaped with '\\'", i)
+		}
+	}
+	return -1, nil
+}
+
+// closeMarkdownV2URL returns the index of the unescaped ')' ending a link
+// url, or -1.
+func closeMarkdownV2URL(runes []rune, from int) int {
+	for i := from; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case ')':
+			return i
+		}
+	}
+	return -1
+}
+
+// ValidateHTML reports the first construct Telegram would refuse to parse:
+// an unsupported or unbalanced tag, a tag missing its required attribute, or
+// a bare '<', '>' or '&'. Positions are in characters.
+func ValidateHTML(text string) error {
+	var open []string
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '<':
+			end := strings.IndexByte(text[i:], '>')
+			if end < 0 {
+				return fmt.Errorf("unescaped '<' at position %d (use &lt;)", position(text, i))
+			}
+			tag := text[i+1 : i+end]
+			if name, ok := strings.CutPrefix(tag, "/"); ok {
+				name = strings.ToLower(strings.TrimSpace(name))
+				if n := len(open); n == 0 || open[n-1] != name {
+					return fmt.Errorf("unexpected closing tag </%s> at position %d", name, position(text, i))
+				}
+				open = open[:len(open)-1]
+			} else {
+				name, attrs, _ := strings.Cut(tag, " ")
+				name = strings.ToLower(name)
+				required, ok := htmlTags[name]
+				if !ok {
+					return fmt.Errorf("unsupported tag <%s> at position %d", name, position(text, i))
+				}
+				if required != "" && !strings.Contains(attrs, required) {
+					return fmt.Errorf("tag <%s> at position %d requires %s", name, position(text, i), strings.TrimSuffix(required, "="))
+				}
+				open = append(open, name)
+			}
+			i += end
+		case '>':
+			return fmt.Errorf("unescaped '>' at position %d (use &gt;)", position(text, i))
+		case '&':
+			end := strings.IndexByte(text[i:], ';')
+			if end < 0 || !isHTMLEntity(text[i+1:i+end]) {
+				return fmt.Errorf("unescaped '&' at position %d (use &amp;)", position(text, i))
+			}
+			i += end
+		}
+	}
+
+	if len(open) > 0 {
+		return fmt.Errorf("unclosed tag <%s>", open[len(open)-1])
+	}
+	return nil
+}
+
+// isHTMLEntity reports whether name is one of the named entities Telegram
+// supports or a numeric character reference.
+func isHTMLEntity(name string) bool {
+	switch name {
+	case "lt", "gt", "amp", "quot":
+		return true
+	}
+	digits, ok := strings.CutPrefix(name, "#")
+	if !ok || digits == "" {
+		return false
+	}
+	base := "0123456789"
+	if hex, ok := strings.CutPrefix(digits, "x"); ok {
+		digits, base = hex, "0123456789abcdefABCDEF"
+	}
+	return digits != "" && strings.Trim(digits, base) == ""
+}
+
+func position(text string, offset int) int {
+	return utf8.RuneCountInString(text[:offset])
+}
@@ -0,0 +1,84 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMarkdownV2(t *testing.T) {
+	cases := []struct {
+		name   string
+		text   string
+		expErr string
+	}{
+		{name: "plain words", text: "Daily standup"},
+		{name: "bold heading and link", text: "*Standup*\nJoin [the call](https://meet.example.com/a?b=c\\)d)"},
+		{name: "nested entities", text: "*bold _italic bold_ ~strike~ __underline__ ||spoiler||*"},
+		{name: "escaped reserved", text: "Release 1\\.2 \\- done\\!"},
+		{name: "code with backtick", text: "`a \\` b` and ```go\nfmt.Println(1)\n```"},
+		{name: "blockquote", text: ">quoted line\nplain"},
+		{name: "unescaped dot", text: "Meeting at 10.30", expErr: "character '.' at position 13 must be escaped"},
+		{name: "unclosed bold", text: "*Standup", expErr: `unclosed "*" entity`},
+		{name: "overlapping entities", text: "*bold _italic* rest_", expErr: "overlaps"},
+		{name: "link without url", text: "[text] here", expErr: "has no url"},
+		{name: "unclosed url", text: "[text](https://example.com", expErr: "unclosed link url"},
+		{name: "unclosed code", text: "`code", expErr: "unclosed \"`\" entity"},
+		{name: "single pipe", text: "a | b", expErr: "must be escaped"},
+		{name: "trailing escape", text: "oops\\", expErr: "invalid escape"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateMarkdownV2(tc.text)
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expErr)
+		})
+	}
+}
+
+func TestValidateHTML(t *testing.T) {
+	cases := []struct {
+		name   string
+		text   string
+		expErr string
+	}{
+		{name: "plain words", text: "Daily standup"},
+		{name: "bold heading and link", text: `<b>Standup</b> <a href="https://meet.example.com">join</a>`},
+		{name: "nested and entities", text: "<i>1 &lt; 2 &amp;&amp; 3 &gt; 2 &#8212; &#x2014;</i>"},
+		{name: "spoiler span", text: `<span class="tg-spoiler">secret</span>`},
+		{name: "unsupported tag", text: "<div>text</div>", expErr: "unsupported tag <div>"},
+		{name: "unbalanced tags", text: "<b><i>text</b></i>", expErr: "unexpected closing tag </b>"},
+		{name: "unclosed tag", text: "<b>text", expErr: "unclosed tag <b>"},
+		{name: "link without href", text: "<a>text</a>", expErr: "requires href"},
+		{name: "bare less-than", text: "Отчет: 1 < 2", expErr: "unescaped '<' at position 9"},
+		{name: "bare ampersand", text: "R&D", expErr: "unescaped '&'"},
+		{name: "unknown entity", text: "&nbsp;", expErr: "unescaped '&'"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateHTML(tc.text)
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expErr)
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	text := "Release 1.2 (beta) - 50% off! a_b *c* [d] \\ <e> & f"
+
+	require.NoError(t, ValidateMarkdownV2(EscapeMarkdownV2(text)))
+	require.Equal(t, "1\\.2 \\\\ \\*", EscapeMarkdownV2("1.2 \\ *"))
+
+	require.NoError(t, ValidateHTML(EscapeHTML(text)))
+	require.Equal(t, "&lt;b&gt; R&amp;D", EscapeHTML("<b> R&D"))
+}
@@ -0,0 +1,169 @@
+package telegram
+
+import (
+	"DelayedNotifier/pkg/logger"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/wb-go/wbf/config"
+	wbfredis "github.com/wb-go/wbf/redis"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultGlobalRateLimit  = 30
+	defaultChatRateLimit    = 1
+	defaultRateLimitMaxWait = 10 * time.Second
+
+	rateLimitGlobalKey     = "telegram:ratelimit:global"
+	rateLimitChatKeyPrefix = "telegram:ratelimit:chat:"
+)
+
+// takeScript refills the global (KEYS[1]) and per-chat (KEYS[2]) token
+// buckets from the time elapsed since their last update and takes one token
+// from each only if both have one. ARGV holds rate (tokens per millisecond)
+// and burst for each bucket. It returns 0 on success or the number of
+// milliseconds to wait before trying again. Redis server time is used so
+// replicas with skewed clocks share the same buckets.
+var takeScript = goredis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local tokens = {}
+local wait = 0
+for i = 1, 2 do
+	local rate = tonumber(ARGV[i * 2 - 1])
+	local burst = tonumber(ARGV[i * 2])
+	local bucket = redis.call('HMGET', KEYS[i], 'tokens', 'ts')
+	local current = tonumber(bucket[1]) or burst
+	local ts = tonumber(bucket[2]) or now
+	current = math.min(burst, current + math.max(0, now - ts) * rate)
+	if current < 1 then
+		wait = math.max(wait, math.ceil((1 - current) / rate))
+	end
+	tokens[i] = current
+end
+
+if wait > 0 then
+	return wait
+end
+
+for i = 1, 2 do
+	local rate = tonumber(ARGV[i * 2 - 1])
+	local burst = tonumber(ARGV[i * 2])
+	redis.call('HSET', KEYS[i], 'tokens', tostring(tokens[i] - 1), 'ts', now)
+	redis.call('PEXPIRE', KEYS[i], math.ceil(burst / rate) + 1000)
+end
+return 0
+`)
+
+// RateLimitError is returned when a send could not get a slot within the
+// configured maximum wait. It is retryable so the notification is rescheduled
+// instead of failed.
+type RateLimitError struct {
+	ChatId     int64
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("telegram rate limit exceeded for chat %d, retry after %s", e.ChatId, e.RetryAfter)
+}
+
+func (e *RateLimitError) Retryable() bool {
+	return true
+}
+
+func (e *RateLimitError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// RateLimiter keeps sends under Telegram flood limits with token buckets
+// stored in Redis, one global and one per chat, so every replica draws from
+// the same budget.
+type RateLimiter struct {
+	client      *wbfredis.Client
+	globalRate  float64
+	globalBurst int
+	chatRate    float64
+	chatBurst   int
+	maxWait     time.Duration
+	ctx         context.Context
+}
+
+func NewRateLimiter(client *wbfredis.Client, cfg *config.Config, ctx context.Context) *RateLimiter {
+	globalRate := cfg.GetFloat64("TELEGRAM_GLOBAL_RATE_LIMIT")
+	if globalRate <= 0 {
+		globalRate = defaultGlobalRateLimit
+	}
+	chatRate := cfg.GetFloat64("TELEGRAM_CHAT_RATE_LIMIT")
+	if chatRate <= 0 {
+		chatRate = defaultChatRateLimit
+	}
+	maxWait := time.Duration(cfg.GetInt("TELEGRAM_RATE_LIMIT_MAX_WAIT_MS")) * time.Millisecond
+	if maxWait <= 0 {
+		maxWait = defaultRateLimitMaxWait
+	}
+
+	return &RateLimiter{
+		client:      client,
+		globalRate:  globalRate,
+		globalBurst: burstFor(globalRate),
+		chatRate:    chatRate,
+		chatBurst:   burstFor(chatRate),
+		maxWait:     maxWait,
+		ctx:         ctx,
+	}
+}
+
+func burstFor(rate float64) int {
+	return max(1, int(math.Ceil(rate)))
+}
+
+// Wait blocks until both the global and the chat bucket allow a message. If
+// that would take longer than the maximum wait it returns a RateLimitError.
+// When Redis is unavailable the send is let through rather than blocked.
+func (l *RateLimiter) Wait(chatId int64) error {
+	deadline := time.Now().Add(l.maxWait)
+	for {
+		wait, err := l.take(chatId)
+		if err != nil {
+			logger.GetLoggerFromCtx(l.ctx).Warn("Telegram rate limiter unavailable, sending without limit",
+				zap.Error(err),
+				zap.Int64("chat_id", chatId))
+			return nil
+		}
+		if wait <= 0 {
+			return nil
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return &RateLimitError{ChatId: chatId, RetryAfter: wait}
+		}
+
+		logger.GetLoggerFromCtx(l.ctx).Debug("Telegram rate limit reached, delaying send",
+			zap.Int64("chat_id", chatId),
+			zap.Duration("wait", wait))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-l.ctx.Done():
+			timer.Stop()
+			return l.ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *RateLimiter) take(chatId int64) (time.Duration, error) {
+	wait, err := takeScript.Run(l.ctx, l.client,
+		[]string{rateLimitGlobalKey, rateLimitChatKeyPrefix + strconv.FormatInt(chatId, 10)},
+		l.globalRate/1000, l.globalBurst, l.chatRate/1000, l.chatBurst,
+	).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
@@ -0,0 +1,159 @@
+package telegram
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/pkg/logger"
+	"context"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Ways of receiving updates, selected by TELEGRAM_UPDATES.
+const (
+	UpdatesPolling = "polling"
+	UpdatesWebhook = "webhook"
+	UpdatesOff     = "off"
+)
+
+// WebhookSecretHeader carries the secret token Telegram sends with every
+// webhook request.
+const WebhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+var allowedUpdates = []string{"message", "callback_query"}
+
+// genericReply is shown instead of errors that are not meant for chat users,
+// e.g. database failures.
+const genericReply = "Something went wrong, please try again later"
+
+// Command is a bot command sent by a user, e.g. "/remind 2h Call mom" has
+// Name "remind" and Args "2h Call mom".
+type Command struct {
+	Name   string
+	Args   string
+	ChatId int64
+}
+
+// UpdateHandler acts on what users do in chats with the bot. HandleCallback
+// applies a pressed callback button, HandleCommand runs a command; both
+// return the text shown to the user in reply.
+type UpdateHandler interface {
+	HandleCallback(cb Callback) (string, error)
+	HandleCommand(cmd Command) (string, error)
+}
+
+type updateClient interface {
+	SendMessage(chatId int64, message, parseMode string, keyboard [][]Button) (int, error)
+	AnswerCallback(callbackId, text string) error
+	RemoveKeyboard(chatId int64, messageId int) error
+	PollUpdates(ctx context.Context, allowed []string, handle func(tgbotapi.Update))
+	SetWebhook(url, secret string, allowed []string) error
+}
+
+// UpdateProcessor turns updates received from Telegram into actions on
+// notifications.
+type UpdateProcessor struct {
+	client  updateClient
+	handler UpdateHandler
+	ctx     context.Context
+}
+
+func NewUpdateProcessor(client *Client, handler UpdateHandler, ctx context.Context) *UpdateProcessor {
+	return &UpdateProcessor{client: client, handler: handler, ctx: ctx}
+}
+
+// Start long-polls Telegram for updates until ctx is done. Only one replica
+// may poll a bot at a time.
+func (p *UpdateProcessor) Start(ctx context.Context) {
+	p.client.PollUpdates(ctx, allowedUpdates, p.HandleUpdate)
+}
+
+// RegisterWebhook makes Telegram push updates to url instead of waiting to be
+// polled; the receiving endpoint passes them to HandleUpdate. Every replica
+// may register the same url and secret.
+func (p *UpdateProcessor) RegisterWebhook(url, secret string) error {
+	return p.client.SetWebhook(url, secret, allowedUpdates)
+}
+
+func (p *UpdateProcessor) HandleUpdate(update tgbotapi.Update) {
+	switch {
+	case update.CallbackQuery != nil:
+		p.handleCallback(update.CallbackQuery)
+	case update.Message != nil && update.Message.IsCommand():
+		p.handleCommand(update.Message)
+	}
+}
+
+func (p *UpdateProcessor) handleCommand(msg *tgbotapi.Message) {
+	cmd := Command{
+		Name:   msg.Command(),
+		Args:   strings.TrimSpace(msg.CommandArguments()),
+		ChatId: msg.Chat.ID,
+	}
+
+	text, err := p.handler.HandleCommand(cmd)
+	if err != nil {
+		logger.GetLoggerFromCtx(p.ctx).Info("Telegram command rejected",
+			zap.Error(err),
+			zap.String("command", cmd.Name),
+			zap.Int64("chat_id", cmd.ChatId))
+		text = replyText(err)
+	}
+
+	if _, err := p.client.SendMessage(cmd.ChatId, text, "", nil); err != nil {
+		logger.GetLoggerFromCtx(p.ctx).Warn("Failed to reply to Telegram command",
+			zap.Error(err),
+			zap.String("command", cmd.Name),
+			zap.Int64("chat_id", cmd.ChatId))
+	}
+}
+
+func (p *UpdateProcessor) handleCallback(query *tgbotapi.CallbackQuery) {
+	cb, err := DecodeCallback(query.Data)
+	if err != nil {
+		p.answer(query.ID, "Unknown button")
+		return
+	}
+	if query.Message != nil {
+		cb.ChatId = query.Message.Chat.ID
+		cb.MessageId = query.Message.MessageID
+	}
+
+	text, err := p.handler.HandleCallback(cb)
+	if err != nil {
+		logger.GetLoggerFromCtx(p.ctx).Warn("Failed to handle Telegram callback",
+			zap.Error(err),
+			zap.String("action", cb.Action),
+			zap.String("notification_id", cb.NotificationId))
+		p.answer(query.ID, replyText(err))
+		return
+	}
+	p.answer(query.ID, text)
+
+	if cb.MessageId != 0 {
+		if err := p.client.RemoveKeyboard(cb.ChatId, cb.MessageId); err != nil {
+			logger.GetLoggerFromCtx(p.ctx).Warn("Failed to remove inline keyboard",
+				zap.Error(err),
+				zap.Int64("chat_id", cb.ChatId),
+				zap.Int("message_id", cb.MessageId))
+		}
+	}
+}
+
+func (p *UpdateProcessor) answer(callbackId, text string) {
+	if err := p.client.AnswerCallback(callbackId, text); err != nil {
+		logger.GetLoggerFromCtx(p.ctx).Warn("Failed to answer Telegram callback",
+			zap.Error(err),
+			zap.String("callback_id", callbackId))
+	}
+}
+
+// replyText returns the text of a rejected request as is and hides the
+// details of any other error behind genericReply.
+func replyText(err error) string {
+	if models.IsInvalid(err) {
+		return err.Error()
+	}
+	return genericReply
+}
@@ -0,0 +1,150 @@
+package telegram
+
+import (
+	"DelayedNotifier/internal/models"
+	"DelayedNotifier/pkg/logger"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUpdateClient struct {
+	messages []string
+	answers  []string
+	removed  []int
+}
+
+func (c *fakeUpdateClient) SendMessage(chatId int64, message, parseMode string, keyboard [][]Button) (int, error) {
+	c.messages = append(c.messages, message)
+	return len(c.messages), nil
+}
+
+func (c *fakeUpdateClient) AnswerCallback(callbackId, text string) error {
+	c.answers = append(c.answers, text)
+	return nil
+}
+
+func (c *fakeUpdateClient) RemoveKeyboard(chatId int64, messageId int) error {
+	c.removed = append(c.removed, messageId)
+	return nil
+}
+
+func (c *fakeUpdateClient) PollUpdates(ctx context.Context, allowed []string, handle func(tgbotapi.Update)) {
+}
+
+func (c *fakeUpdateClient) SetWebhook(url, secret string, allowed []string) error {
+	return nil
+}
+
+type fakeUpdateHandler struct {
+	callbacks []Callback
+	commands  []Command
+	err       error
+}
+
+func (h *fakeUpdateHandler) HandleCommand(cmd Command) (string, error) {
+	h.commands = append(h.commands, cmd)
+	if h.err != nil {
+		return "", h.err
+	}
+	return "Reminder set", nil
+}
+
+func (h *fakeUpdateHandler) HandleCallback(cb Callback) (string, error) {
+	h.callbacks = append(h.callbacks, cb)
+	if h.err != nil {
+		return "", h.err
+	}
+	return "Done", nil
+}
+
+func TestEncodeCallback(t *testing.T) {
+	id := "6f1c1a52-3c4b-4f7e-9a55-2b0c6d7e8f90"
+
+	data, err := EncodeCallback("snooze", id, "10m")
+	require.NoError(t, err)
+	require.Equal(t, "snooze:"+id+":10m", data)
+
+	cb, err := DecodeCallback(data)
+	require.NoError(t, err)
+	require.Equal(t, Callback{Action: "snooze", NotificationId: id, Arg: "10m"}, cb)
+
+	cb, err = DecodeCallback("ack:" + id)
+	require.NoError(t, err)
+	require.Equal(t, Callback{Action: "ack", NotificationId: id}, cb)
+
+	_, err = EncodeCallback("snooze", id, strings.Repeat("1", 30)+"m")
+	require.ErrorContains(t, err, "exceeds 64 bytes")
+
+	_, err = DecodeCallback("ack")
+	require.Error(t, err)
+}
+
+func TestUpdateProcessorHandleCallback(t *testing.T) {
+	ctx, err := logger.New(context.Background())
+	require.NoError(t, err)
+	client := &fakeUpdateClient{}
+	handler := &fakeUpdateHandler{}
+	processor := &UpdateProcessor{client: client, handler: handler, ctx: ctx}
+
+	update := tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "cb-1",
+		Data:    "ack:test-id",
+		Message: &tgbotapi.Message{MessageID: 77, Chat: &tgbotapi.Chat{ID: 42}},
+	}}
+	processor.HandleUpdate(update)
+
+	require.Equal(t, []Callback{{Action: "ack", NotificationId: "test-id", ChatId: 42, MessageId: 77}}, handler.callbacks)
+	require.Equal(t, []string{"Done"}, client.answers)
+	require.Equal(t, []int{77}, client.removed)
+
+	handler.err = models.Invalid(errors.New("notification test-id cannot be cancelled in status sent"))
+	processor.HandleUpdate(update)
+	require.Equal(t, "notification test-id cannot be cancelled in status sent", client.answers[1])
+	require.Len(t, client.removed, 1)
+
+	handler.err = errors.New("pq: connection refused")
+	processor.HandleUpdate(update)
+	require.Equal(t, genericReply, client.answers[2])
+	require.Len(t, client.removed, 1)
+
+	update.CallbackQuery.Data = "garbage"
+	processor.HandleUpdate(update)
+	require.Equal(t, "Unknown button", client.answers[3])
+	require.Len(t, handler.callbacks, 3)
+}
+
+func TestUpdateProcessorHandleCommand(t *testing.T) {
+	ctx, err := logger.New(context.Background())
+	require.NoError(t, err)
+	client := &fakeUpdateClient{}
+	handler := &fakeUpdateHandler{}
+	processor := &UpdateProcessor{client: client, handler: handler, ctx: ctx}
+
+	text := "/remind 2h Call mom"
+	update := tgbotapi.Update{Message: &tgbotapi.Message{
+		Text:     text,
+		Chat:     &tgbotapi.Chat{ID: 42},
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/remind")}},
+	}}
+	processor.HandleUpdate(update)
+
+	require.Equal(t, []Command{{Name: "remind", Args: "2h Call mom", ChatId: 42}}, handler.commands)
+	require.Equal(t, []string{"Reminder set"}, client.messages)
+
+	handler.err = models.Invalid(errors.New("unknown command /foo, see /help"))
+	processor.HandleUpdate(update)
+	require.Equal(t, "unknown command /foo, see /help", client.messages[1])
+
+	handler.err = errors.New("pq: connection refused")
+	processor.HandleUpdate(update)
+	require.Equal(t, genericReply, client.messages[2])
+
+	processor.HandleUpdate(tgbotapi.Update{Message: &tgbotapi.Message{Text: "hello", Chat: &tgbotapi.Chat{ID: 42}}})
+	require.Len(t, handler.commands, 3)
+	require.Len(t, client.messages, 3)
+}
@@ -4,33 +4,67 @@ package transport
 
 import (
 	"DelayedNotifier/internal/models"
+	"DelayedNotifier/internal/storage"
+	"DelayedNotifier/internal/telegram"
 	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/wb-go/wbf/config"
 	"github.com/wb-go/wbf/ginext"
 )
 
+const (
+	// multipartOverhead is allowed on top of the attachment size for the
+	// notification field and the multipart headers.
+	multipartOverhead  = 1 << 20
+	maxMultipartMemory = 32 << 20
+)
+
 type ServiceDelayedNotifierInterface interface {
 	CreateNotification(*models.Notification) (string, error)
-	GetNotificationStatus(id string) (string, error)
+	CreateNotificationWithAttachment(nf *models.Notification, fileName string, file io.Reader) (string, error)
+	GetNotification(id string) (*models.Notification, error)
+	GetNotificationEvents(id string) ([]*models.NotificationEvent, error)
+	RescheduleNotification(id string, req *models.Notification) (*models.Notification, error)
 	DeleteNotification(id string) error
+	EditSentMessage(id string, req *models.Notification) (*models.Notification, error)
+	RetractNotification(id string) error
 	ProcessNotification(nf *models.Notification) error
-	GetAllNotifications() ([]*models.Notification, error)
+	ListNotifications(filter *models.NotificationFilter) (*models.NotificationPage, error)
+}
+
+// TelegramUpdateHandler acts on updates Telegram pushes to the webhook.
+type TelegramUpdateHandler interface {
+	HandleUpdate(update tgbotapi.Update)
 }
 
 type Server struct {
 	ctx     context.Context
 	cfg     *config.Config
 	Service ServiceDelayedNotifierInterface
+
+	telegramUpdates TelegramUpdateHandler
+	telegramSecret  string
 }
 
 func NewServer(ctx context.Context, cfg *config.Config, srv ServiceDelayedNotifierInterface) *Server {
 	return &Server{ctx: ctx, cfg: cfg, Service: srv}
 }
 
+// EnableTelegramWebhook serves POST /telegram/webhook, passing updates that
+// carry secret to handler.
+func (s *Server) EnableTelegramWebhook(handler TelegramUpdateHandler, secret string) {
+	s.telegramUpdates = handler
+	s.telegramSecret = secret
+}
+
 func (s *Server) Run() error {
 	eng := ginext.New("release")
 	eng.Use(ginext.Logger())
@@ -41,9 +75,17 @@ func (s *Server) Run() error {
 	v1 := eng.Group("/api/v1")
 	v1.POST("/notify", s.NotifyCreateHandler())
 	v1.GET("/notify/:id", s.NotifyGetHandler())
+	v1.GET("/notify/:id/events", s.NotifyEventsHandler())
+	v1.PATCH("/notify/:id", s.NotifyRescheduleHandler())
 	v1.DELETE("/notify/:id", s.NotifyDeleteHandler())
+	v1.PATCH("/notify/:id/sent-message", s.NotifyEditSentHandler())
+	v1.POST("/notify/:id/retract", s.NotifyRetractHandler())
 	v1.GET("/notifications", s.GetAllNotificationsHandler())
 
+	if s.telegramUpdates != nil {
+		eng.POST("/telegram/webhook", s.TelegramWebhookHandler())
+	}
+
 	return eng.Run(s.cfg.GetString("HOST") + ":" + s.cfg.GetString("PORT"))
 }
 
@@ -55,6 +97,10 @@ func (s *Server) NotifyCreateHandler() gin.HandlerFunc {
 				return
 			}
 		}()
+		if c.ContentType() == gin.MIMEMultipartPOSTForm {
+			s.notifyCreateWithAttachment(c)
+			return
+		}
 		var Request *models.Notification
 		if err := c.ShouldBindJSON(&Request); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -62,11 +108,61 @@ func (s *Server) NotifyCreateHandler() gin.HandlerFunc {
 		}
 		id, err := s.Service.CreateNotification(Request)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
 		}
 		c.JSON(http.StatusOK, gin.H{"id": id})
 	}
 }
+
+// notifyCreateWithAttachment handles a multipart upload: the notification
+// JSON in the "notification" field and the attached file in "file".
+func (s *Server) notifyCreateWithAttachment(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, storage.MaxSize(s.cfg)+multipartOverhead)
+	if err := c.Request.ParseMultipartForm(maxMultipartMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid multipart form: " + err.Error()})
+		return
+	}
+
+	var Request models.Notification
+	if err := json.Unmarshal([]byte(c.PostForm("notification")), &Request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification field: " + err.Error()})
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required: " + err.Error()})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	id, err := s.Service.CreateNotificationWithAttachment(&Request, header.Filename, file)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// errorStatus answers errors caused by the request with 400 and everything
+// else with 500.
+func errorStatus(err error) int {
+	if models.IsInvalid(err) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 func (s *Server) NotifyGetHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
@@ -76,12 +172,53 @@ func (s *Server) NotifyGetHandler() gin.HandlerFunc {
 			}
 		}()
 		id := c.Param("id")
-		status, err := s.Service.GetNotificationStatus(id)
+		nf, err := s.Service.GetNotification(id)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"status": status})
+		c.JSON(http.StatusOK, nf)
+	}
+}
+
+func (s *Server) NotifyEventsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+		}()
+		id := c.Param("id")
+		events, err := s.Service.GetNotificationEvents(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"events": events})
+	}
+}
+
+func (s *Server) NotifyRescheduleHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+		}()
+		id := c.Param("id")
+		var Request *models.Notification
+		if err := c.ShouldBindJSON(&Request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		nf, err := s.Service.RescheduleNotification(id, Request)
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, nf)
 	}
 }
 
@@ -96,10 +233,50 @@ func (s *Server) NotifyDeleteHandler() gin.HandlerFunc {
 		id := c.Param("id")
 		err := s.Service.DeleteNotification(id)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"status": fmt.Sprintf("notify %s is deleted", id)})
+		c.JSON(http.StatusOK, gin.H{"status": fmt.Sprintf("notify %s is cancelled", id)})
+	}
+}
+
+func (s *Server) NotifyEditSentHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+		}()
+		id := c.Param("id")
+		var Request *models.Notification
+		if err := c.ShouldBindJSON(&Request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		nf, err := s.Service.EditSentMessage(id, Request)
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, nf)
+	}
+}
+
+func (s *Server) NotifyRetractHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+		}()
+		id := c.Param("id")
+		if err := s.Service.RetractNotification(id); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": fmt.Sprintf("notify %s is retracted", id)})
 	}
 }
 
@@ -111,12 +288,40 @@ func (s *Server) GetAllNotificationsHandler() gin.HandlerFunc {
 				return
 			}
 		}()
-		notifications, err := s.Service.GetAllNotifications()
+		var filter models.NotificationFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		page, err := s.Service.ListNotifications(&filter)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+func (s *Server) TelegramWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+		}()
+		token := c.GetHeader(telegram.WebhookSecretHeader)
+		if s.telegramSecret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.telegramSecret)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid secret token"})
+			return
+		}
+		var update tgbotapi.Update
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, notifications)
+		s.telegramUpdates.HandleUpdate(update)
+		c.Status(http.StatusOK)
 	}
 }
 
@@ -3,15 +3,21 @@ package transport
 import (
 	"DelayedNotifier/internal/models"
 	"DelayedNotifier/internal/service/mocks"
+	"DelayedNotifier/internal/telegram"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/stretchr/testify/require"
 	"github.com/wb-go/wbf/config"
 	"go.uber.org/mock/gomock"
@@ -83,6 +89,14 @@ func TestNotifyCreateHandler_Fail(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			setupMock:      func(m *mocks.MockServiceDelayedNotifierInterface) {},
 		},
+		{
+			name:           "validation error",
+			requestBody:    `{"message": "Test", "channel": "email"}`,
+			expectedStatus: http.StatusBadRequest,
+			setupMock: func(m *mocks.MockServiceDelayedNotifierInterface) {
+				m.EXPECT().CreateNotification(gomock.Any()).Return("", models.Invalid(errors.New("recipient is required for email channel"))).Times(1)
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -141,6 +155,8 @@ func TestNotifyCreateHandler_ServiceError(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	require.JSONEq(t, `{"error": "service error"}`, w.Body.String())
 }
 
 func TestNotifyGetHandler_Success(t *testing.T) {
@@ -149,9 +165,18 @@ func TestNotifyGetHandler_Success(t *testing.T) {
 
 	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
 	notifID := "test-id-123"
-	expectedStatus := "created"
+	sentAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	nf := &models.Notification{
+		Id:           notifID,
+		Message:      "Hi",
+		Status:       "sent",
+		Attempts:     2,
+		LastError:    "timeout",
+		DeliveredVia: "telegram",
+		SentAt:       &sentAt,
+	}
 
-	srv.EXPECT().GetNotificationStatus(notifID).Return(expectedStatus, nil).Times(1)
+	srv.EXPECT().GetNotification(notifID).Return(nf, nil).Times(1)
 
 	cfg := &config.Config{}
 	ctx := context.Background()
@@ -166,10 +191,16 @@ func TestNotifyGetHandler_Success(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, w.Code)
 
-	var response map[string]string
+	var response map[string]any
 	err := json.NewDecoder(w.Body).Decode(&response)
 	require.NoError(t, err)
-	require.Equal(t, expectedStatus, response["status"])
+	require.Equal(t, "sent", response["status"])
+	require.Equal(t, float64(2), response["attempts"])
+	require.Equal(t, "timeout", response["last_error"])
+	require.Equal(t, "telegram", response["delivered_via"])
+	require.Equal(t, "2026-01-02T03:04:05Z", response["sent_at"])
+	require.Contains(t, response, "created_at")
+	require.Contains(t, response, "send_at")
 }
 
 func TestNotifyGetHandler_Fail(t *testing.T) {
@@ -180,7 +211,7 @@ func TestNotifyGetHandler_Fail(t *testing.T) {
 	notifID := "test-id-123"
 	expectedErr := errors.New("notification not found")
 
-	srv.EXPECT().GetNotificationStatus(notifID).Return("", expectedErr).Times(1)
+	srv.EXPECT().GetNotification(notifID).Return(nil, expectedErr).Times(1)
 
 	cfg := &config.Config{}
 	ctx := context.Background()
@@ -196,6 +227,111 @@ func TestNotifyGetHandler_Fail(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestNotifyEventsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
+	sendAt := time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC)
+	events := []*models.NotificationEvent{
+		{Id: 1, NotificationId: "test-id-123", Event: models.EventCreated, Status: "created", SendAt: sendAt},
+		{Id: 2, NotificationId: "test-id-123", Event: models.EventFailed, Status: "failed", Attempt: 3, SendAt: sendAt, WorkerId: "worker-1", Error: "chat not found"},
+	}
+	gomock.InOrder(
+		srv.EXPECT().GetNotificationEvents("test-id-123").Return(events, nil),
+		srv.EXPECT().GetNotificationEvents("missing").Return(nil, errors.New("notification not found: missing")),
+	)
+
+	server := NewServer(context.Background(), &config.Config{}, srv)
+	router := gin.New()
+	router.GET("/api/v1/notify/:id/events", server.NotifyEventsHandler())
+
+	req := httptest.NewRequest("GET", "/api/v1/notify/test-id-123/events", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Events []*models.NotificationEvent `json:"events"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Events, 2)
+	require.Equal(t, models.EventFailed, response.Events[1].Event)
+	require.Equal(t, 3, response.Events[1].Attempt)
+	require.Equal(t, "worker-1", response.Events[1].WorkerId)
+	require.Equal(t, "chat not found", response.Events[1].Error)
+
+	req = httptest.NewRequest("GET", "/api/v1/notify/missing/events", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Contains(t, w.Body.String(), "notification not found")
+}
+
+func TestNotifyRescheduleHandler_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
+	notifID := "test-id-123"
+	updated := &models.Notification{
+		Id:      notifID,
+		Message: "Test notification",
+		Time:    "2026-02-13T16:00:00+03:00",
+		Status:  "created",
+		ChatId:  123456789,
+		Version: 1,
+	}
+
+	srv.EXPECT().RescheduleNotification(notifID, gomock.Any()).Return(updated, nil).Times(1)
+
+	cfg := &config.Config{}
+	ctx := context.Background()
+	server := NewServer(ctx, cfg, srv)
+
+	router := gin.New()
+	router.PATCH("/api/v1/notify/:id", server.NotifyRescheduleHandler())
+
+	req := httptest.NewRequest("PATCH", "/api/v1/notify/"+notifID, bytes.NewBufferString(`{"time": "2026-02-13T16:00:00+03:00"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Notification
+	err := json.NewDecoder(w.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Equal(t, notifID, response.Id)
+	require.Equal(t, 1, response.Version)
+}
+
+func TestNotifyRescheduleHandler_Fail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
+	notifID := "test-id-123"
+	expectedErr := models.Invalid(errors.New("notification test-id-123 cannot be rescheduled in status sent"))
+
+	srv.EXPECT().RescheduleNotification(notifID, gomock.Any()).Return(nil, expectedErr).Times(1)
+
+	cfg := &config.Config{}
+	ctx := context.Background()
+	server := NewServer(ctx, cfg, srv)
+
+	router := gin.New()
+	router.PATCH("/api/v1/notify/:id", server.NotifyRescheduleHandler())
+
+	req := httptest.NewRequest("PATCH", "/api/v1/notify/"+notifID, bytes.NewBufferString(`{"time": "2026-02-13T16:00:00+03:00"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestNotifyDeleteHandler_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -222,7 +358,7 @@ func TestNotifyDeleteHandler_Success(t *testing.T) {
 	err := json.NewDecoder(w.Body).Decode(&response)
 	require.NoError(t, err)
 	require.Contains(t, response["status"], notifID)
-	require.Contains(t, response["status"], "deleted")
+	require.Contains(t, response["status"], "cancelled")
 }
 
 func TestNotifyDeleteHandler_Fail(t *testing.T) {
@@ -231,7 +367,7 @@ func TestNotifyDeleteHandler_Fail(t *testing.T) {
 
 	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
 	notifID := "test-id-123"
-	expectedErr := errors.New("notification not found")
+	expectedErr := models.Invalid(errors.New("notification test-id-123 cannot be cancelled in status sent"))
 
 	srv.EXPECT().DeleteNotification(notifID).Return(expectedErr).Times(1)
 
@@ -246,6 +382,80 @@ func TestNotifyDeleteHandler_Fail(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNotifyEditSentHandler_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
+	notifID := "test-id-123"
+	updated := &models.Notification{
+		Id:         notifID,
+		Message:    "Standup moved to 11:00",
+		Status:     "sent",
+		ChatId:     123456789,
+		MessageIds: []int{42},
+	}
+
+	srv.EXPECT().EditSentMessage(notifID, gomock.Any()).
+		DoAndReturn(func(id string, req *models.Notification) (*models.Notification, error) {
+			require.Equal(t, "Standup moved to 11:00", req.Message)
+			return updated, nil
+		}).Times(1)
+
+	server := NewServer(context.Background(), &config.Config{}, srv)
+	router := gin.New()
+	router.PATCH("/api/v1/notify/:id/sent-message", server.NotifyEditSentHandler())
+
+	req := httptest.NewRequest("PATCH", "/api/v1/notify/"+notifID+"/sent-message", bytes.NewBufferString(`{"message": "Standup moved to 11:00"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Notification
+	err := json.NewDecoder(w.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Equal(t, "Standup moved to 11:00", response.Message)
+	require.Equal(t, []int{42}, response.MessageIds)
+}
+
+func TestNotifyRetractHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
+	gomock.InOrder(
+		srv.EXPECT().RetractNotification("test-id-123").Return(nil),
+		srv.EXPECT().RetractNotification("test-id-123").Return(models.Invalid(errors.New("notification test-id-123 cannot be retracted in status retracted"))),
+		srv.EXPECT().RetractNotification("test-id-123").Return(errors.New("failed to retract sent message: telegram unavailable")),
+	)
+
+	server := NewServer(context.Background(), &config.Config{}, srv)
+	router := gin.New()
+	router.POST("/api/v1/notify/:id/retract", server.NotifyRetractHandler())
+
+	req := httptest.NewRequest("POST", "/api/v1/notify/test-id-123/retract", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"status": "notify test-id-123 is retracted"}`, w.Body.String())
+
+	req = httptest.NewRequest("POST", "/api/v1/notify/test-id-123/retract", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "cannot be retracted in status retracted")
+
+	req = httptest.NewRequest("POST", "/api/v1/notify/test-id-123/retract", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
 	require.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
@@ -271,7 +481,18 @@ func TestGetAllNotificationsHandler_Success(t *testing.T) {
 		},
 	}
 
-	srv.EXPECT().GetAllNotifications().Return(expectedNotifications, nil).Times(1)
+	srv.EXPECT().ListNotifications(&models.NotificationFilter{
+		Status: "created,sent",
+		ChatId: 123456789,
+		From:   "2026-02-13T00:00:00Z",
+		Sort:   "time",
+		Limit:  2,
+		Cursor: "abc",
+	}).Return(&models.NotificationPage{
+		Notifications: expectedNotifications,
+		Total:         5,
+		NextCursor:    "def",
+	}, nil).Times(1)
 
 	cfg := &config.Config{}
 	ctx := context.Background()
@@ -280,18 +501,20 @@ func TestGetAllNotificationsHandler_Success(t *testing.T) {
 	router := gin.New()
 	router.GET("/api/v1/notifications", server.GetAllNotificationsHandler())
 
-	req := httptest.NewRequest("GET", "/api/v1/notifications", nil)
+	req := httptest.NewRequest("GET", "/api/v1/notifications?status=created,sent&chat_id=123456789&from=2026-02-13T00:00:00Z&sort=time&limit=2&cursor=abc", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	require.Equal(t, http.StatusOK, w.Code)
 
-	var response []*models.Notification
+	var response models.NotificationPage
 	err := json.NewDecoder(w.Body).Decode(&response)
 	require.NoError(t, err)
-	require.Len(t, response, 2)
-	require.Equal(t, expectedNotifications[0].Id, response[0].Id)
-	require.Equal(t, expectedNotifications[1].Id, response[1].Id)
+	require.Len(t, response.Notifications, 2)
+	require.Equal(t, expectedNotifications[0].Id, response.Notifications[0].Id)
+	require.Equal(t, expectedNotifications[1].Id, response.Notifications[1].Id)
+	require.Equal(t, 5, response.Total)
+	require.Equal(t, "def", response.NextCursor)
 }
 
 func TestGetAllNotificationsHandler_Fail(t *testing.T) {
@@ -301,7 +524,14 @@ func TestGetAllNotificationsHandler_Fail(t *testing.T) {
 	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
 	expectedErr := errors.New("database error")
 
-	srv.EXPECT().GetAllNotifications().Return(nil, expectedErr).Times(1)
+	gomock.InOrder(
+		srv.EXPECT().ListNotifications(gomock.Any()).Return(nil, expectedErr),
+		srv.EXPECT().ListNotifications(gomock.Any()).
+			DoAndReturn(func(filter *models.NotificationFilter) (*models.NotificationPage, error) {
+				require.Equal(t, "bogus", filter.Status)
+				return nil, models.Invalid(errors.New(`unknown status "bogus"`))
+			}),
+	)
 
 	cfg := &config.Config{}
 	ctx := context.Background()
@@ -315,4 +545,133 @@ func TestGetAllNotificationsHandler_Fail(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	req = httptest.NewRequest("GET", "/api/v1/notifications?status=bogus", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "unknown status")
+
+	req = httptest.NewRequest("GET", "/api/v1/notifications?chat_id=abc", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNotifyCreateHandler_Attachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
+	srv.EXPECT().CreateNotificationWithAttachment(gomock.Any(), "report.pdf", gomock.Any()).
+		DoAndReturn(func(nf *models.Notification, fileName string, file io.Reader) (string, error) {
+			require.Equal(t, "Weekly report", nf.Message)
+			require.Equal(t, int64(123456789), nf.ChatId)
+			data, err := io.ReadAll(file)
+			require.NoError(t, err)
+			require.Equal(t, "%PDF-1.7", string(data))
+			return "test-id-123", nil
+		}).Times(1)
+
+	server := NewServer(context.Background(), config.New(), srv)
+	router := gin.New()
+	router.POST("/api/v1/notify", server.NotifyCreateHandler())
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("notification", `{"message": "Weekly report", "chat_id": 123456789}`))
+	part, err := form.CreateFormFile("file", "report.pdf")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("%PDF-1.7"))
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest("POST", "/api/v1/notify", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"id": "test-id-123"}`, w.Body.String())
+
+	body.Reset()
+	form = multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("notification", `{"message": "Weekly report"}`))
+	require.NoError(t, form.Close())
+
+	req = httptest.NewRequest("POST", "/api/v1/notify", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "file is required")
+
+	cfg := config.New()
+	cfg.SetDefault("ATTACHMENT_MAX_SIZE_MB", 1)
+	server = NewServer(context.Background(), cfg, srv)
+	router = gin.New()
+	router.POST("/api/v1/notify", server.NotifyCreateHandler())
+
+	body.Reset()
+	form = multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("notification", `{"message": "Weekly report", "chat_id": 123456789}`))
+	part, err = form.CreateFormFile("file", "huge.bin")
+	require.NoError(t, err)
+	_, err = part.Write(make([]byte, 3<<20))
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req = httptest.NewRequest("POST", "/api/v1/notify", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestTelegramWebhookHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := mocks.NewMockServiceDelayedNotifierInterface(ctrl)
+	updates := mocks.NewMockTelegramUpdateHandler(ctrl)
+	updates.EXPECT().HandleUpdate(gomock.Any()).
+		Do(func(update tgbotapi.Update) {
+			require.Equal(t, 7, update.UpdateID)
+			require.Equal(t, "ack:test-id", update.CallbackQuery.Data)
+		}).Times(1)
+
+	server := NewServer(context.Background(), &config.Config{}, srv)
+	server.EnableTelegramWebhook(updates, "s3cret")
+	router := gin.New()
+	router.POST("/telegram/webhook", server.TelegramWebhookHandler())
+
+	body := `{"update_id": 7, "callback_query": {"id": "1", "data": "ack:test-id"}}`
+	cases := []struct {
+		name    string
+		secret  string
+		body    string
+		expCode int
+	}{
+		{name: "no secret", body: body, expCode: http.StatusUnauthorized},
+		{name: "wrong secret", secret: "guess", body: body, expCode: http.StatusUnauthorized},
+		{name: "invalid json", secret: "s3cret", body: "{", expCode: http.StatusBadRequest},
+		{name: "update", secret: "s3cret", body: body, expCode: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/telegram/webhook", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.secret != "" {
+				req.Header.Set(telegram.WebhookSecretHeader, tc.secret)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, tc.expCode, w.Code)
+		})
+	}
 }
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"DelayedNotifier/pkg/logger"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wb-go/wbf/config"
+	"go.uber.org/zap"
+)
+
+const (
+	SignatureHeader = "X-Signature-256"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// StatusError is a non-2xx response. RetryAfter is the wait the endpoint
+// asked for in its Retry-After header, if any.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout
+}
+
+func (e *StatusError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+type Client struct {
+	httpClient *http.Client
+	secret     []byte
+	ctx        context.Context
+}
+
+func NewClient(cfg *config.Config, ctx context.Context) *Client {
+	timeout := time.Duration(cfg.GetInt("WEBHOOK_TIMEOUT_MS")) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: timeout},
+		secret:     []byte(cfg.GetString("WEBHOOK_SECRET")),
+		ctx:        ctx,
+	}
+}
+
+func Sign(secret []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SendWebhook makes a single delivery attempt. Failed attempts are retried
+// by the caller's retry policy: a *StatusError reports through Retryable
+// whether the response is worth retrying, network errors always are.
+func (c *Client) SendWebhook(url string, headers map[string]string, payload []byte) error {
+	if err := c.post(url, headers, payload); err != nil {
+		logger.GetLoggerFromCtx(c.ctx).Warn("Webhook delivery failed",
+			zap.Error(err),
+			zap.String("url", url))
+		return err
+	}
+
+	logger.GetLoggerFromCtx(c.ctx).Info("Webhook delivered successfully",
+		zap.String("url", url))
+	return nil
+}
+
+func (c *Client) post(url string, headers map[string]string, payload []byte) error {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(c.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(c.secret, payload))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			statusErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return statusErr
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"DelayedNotifier/pkg/logger"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) *Client {
+	ctx, err := logger.New(context.Background())
+	require.NoError(t, err)
+	return &Client{
+		httpClient: &http.Client{Timeout: time.Second},
+		secret:     []byte("test-secret"),
+		ctx:        ctx,
+	}
+}
+
+func TestClient_SendWebhookSignsPayload(t *testing.T) {
+	payload := []byte(`{"id":"test-id","message":"Test message"}`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, payload, body)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		require.Equal(t, Sign([]byte("test-secret"), body), r.Header.Get(SignatureHeader))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := newTestClient(t).SendWebhook(server.URL, map[string]string{"Authorization": "Bearer token"}, payload)
+	require.NoError(t, err)
+}
+
+func TestClient_SendWebhookMakesSingleAttempt(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := newTestClient(t).SendWebhook(server.URL, nil, []byte(`{}`))
+	require.Error(t, err)
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.True(t, statusErr.Retryable())
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_SendWebhookReportsRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	err := newTestClient(t).SendWebhook(server.URL, nil, []byte(`{}`))
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.True(t, statusErr.Retryable())
+	require.Equal(t, 30*time.Second, statusErr.RetryDelay())
+}
+
+func TestClient_SendWebhookDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := newTestClient(t).SendWebhook(server.URL, nil, []byte(`{}`))
+	require.Error(t, err)
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	require.False(t, statusErr.Retryable())
+	require.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1 @@
+ALTER TABLE notifications ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+CREATE TABLE notification_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id VARCHAR(255) NOT NULL,
+    payload BYTEA NOT NULL,
+    routing_key VARCHAR(255) NOT NULL,
+    send_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notification_outbox_pending ON notification_outbox (id) WHERE processed_at IS NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE scheduled_messages (
+    id BIGSERIAL PRIMARY KEY,
+    routing_key VARCHAR(255) NOT NULL,
+    payload BYTEA NOT NULL,
+    due_at TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    dead_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scheduled_messages_due ON scheduled_messages (due_at) WHERE dead_at IS NULL;
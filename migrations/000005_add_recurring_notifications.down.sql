@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS notification_occurrences;
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS schedule,
+    DROP COLUMN IF EXISTS end_at,
+    DROP COLUMN IF EXISTS max_occurrences,
+    DROP COLUMN IF EXISTS occurrences;
@@ -0,0 +1,15 @@
+ALTER TABLE notifications
+    ADD COLUMN schedule VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN end_at VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN max_occurrences INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN occurrences INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE notification_occurrences (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id VARCHAR(255) NOT NULL,
+    occurrence INTEGER NOT NULL,
+    scheduled_time VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    fired_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (notification_id, occurrence)
+);
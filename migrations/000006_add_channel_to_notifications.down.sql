@@ -0,0 +1,4 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS channel,
+    DROP COLUMN IF EXISTS recipient,
+    DROP COLUMN IF EXISTS subject;
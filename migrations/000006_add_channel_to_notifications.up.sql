@@ -0,0 +1,4 @@
+ALTER TABLE notifications
+    ADD COLUMN channel VARCHAR(50) NOT NULL DEFAULT 'telegram',
+    ADD COLUMN recipient VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN subject TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS headers,
+    DROP COLUMN IF EXISTS payload,
+    ALTER COLUMN recipient TYPE VARCHAR(255);
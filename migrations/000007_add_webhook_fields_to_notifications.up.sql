@@ -0,0 +1,4 @@
+ALTER TABLE notifications
+    ALTER COLUMN recipient TYPE TEXT,
+    ADD COLUMN headers JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN payload JSONB;
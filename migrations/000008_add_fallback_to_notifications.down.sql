@@ -0,0 +1,3 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS fallback,
+    DROP COLUMN IF EXISTS delivered_via;
@@ -0,0 +1,3 @@
+ALTER TABLE notifications
+    ADD COLUMN fallback JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN delivered_via VARCHAR(50) NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS max_attempts,
+    DROP COLUMN IF EXISTS backoff,
+    DROP COLUMN IF EXISTS max_age;
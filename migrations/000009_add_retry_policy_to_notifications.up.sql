@@ -0,0 +1,5 @@
+ALTER TABLE notifications
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN max_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN backoff VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN max_age VARCHAR(50) NOT NULL DEFAULT '';
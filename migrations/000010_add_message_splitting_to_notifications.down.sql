@@ -0,0 +1,3 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS split,
+    DROP COLUMN IF EXISTS message_ids;
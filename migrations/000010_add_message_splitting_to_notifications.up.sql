@@ -0,0 +1,3 @@
+ALTER TABLE notifications
+    ADD COLUMN split BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN message_ids JSONB NOT NULL DEFAULT '[]';
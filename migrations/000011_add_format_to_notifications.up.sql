@@ -0,0 +1,2 @@
+ALTER TABLE notifications
+    ADD COLUMN format VARCHAR(20) NOT NULL DEFAULT 'plain';
@@ -0,0 +1,3 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS buttons,
+    DROP COLUMN IF EXISTS acknowledged_at;
@@ -0,0 +1,3 @@
+ALTER TABLE notifications
+    ADD COLUMN buttons JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN acknowledged_at VARCHAR(255) NOT NULL DEFAULT '';
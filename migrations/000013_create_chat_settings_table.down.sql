@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notifications_chat_id_status;
+
+DROP TABLE IF EXISTS chat_settings;
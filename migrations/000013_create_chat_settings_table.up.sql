@@ -0,0 +1,6 @@
+CREATE TABLE chat_settings (
+    chat_id BIGINT PRIMARY KEY,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC'
+);
+
+CREATE INDEX idx_notifications_chat_id_status ON notifications (chat_id, status);
@@ -0,0 +1,2 @@
+ALTER TABLE notifications
+    ADD COLUMN attachment JSONB;
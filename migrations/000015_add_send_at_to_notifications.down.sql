@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notifications_channel_send_at_id;
+DROP INDEX IF EXISTS idx_notifications_chat_id_send_at_id;
+DROP INDEX IF EXISTS idx_notifications_status_send_at_id;
+DROP INDEX IF EXISTS idx_notifications_send_at_id;
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS send_at;
@@ -0,0 +1,11 @@
+ALTER TABLE notifications ADD COLUMN send_at TIMESTAMPTZ;
+
+UPDATE notifications SET send_at = COALESCE(NULLIF(time, '')::timestamptz, now());
+
+ALTER TABLE notifications ALTER COLUMN send_at SET NOT NULL;
+ALTER TABLE notifications ALTER COLUMN send_at SET DEFAULT now();
+
+CREATE INDEX idx_notifications_send_at_id ON notifications (send_at, id);
+CREATE INDEX idx_notifications_status_send_at_id ON notifications (status, send_at, id);
+CREATE INDEX idx_notifications_chat_id_send_at_id ON notifications (chat_id, send_at, id);
+CREATE INDEX idx_notifications_channel_send_at_id ON notifications (channel, send_at, id);
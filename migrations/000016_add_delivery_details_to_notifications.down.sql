@@ -0,0 +1,4 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS sent_at,
+    DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,4 @@
+ALTER TABLE notifications
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN sent_at TIMESTAMPTZ,
+    ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+CREATE TABLE notification_events (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id VARCHAR(255) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    attempt INTEGER NOT NULL DEFAULT 0,
+    send_at TIMESTAMPTZ NOT NULL,
+    worker_id VARCHAR(255) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_events_notification ON notification_events (notification_id, id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notifications_lease_until;
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS lease_until;
@@ -0,0 +1,6 @@
+ALTER TABLE notifications
+    ADD COLUMN lease_until TIMESTAMPTZ;
+
+CREATE INDEX idx_notifications_lease_until ON notifications (lease_until) WHERE status = 'sending';
+
+UPDATE notifications SET lease_until = now() + INTERVAL '5 minutes' WHERE status = 'sending';
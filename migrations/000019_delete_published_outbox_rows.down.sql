@@ -0,0 +1,3 @@
+ALTER TABLE notification_outbox ADD COLUMN processed_at TIMESTAMPTZ;
+
+CREATE INDEX idx_notification_outbox_pending ON notification_outbox (id) WHERE processed_at IS NULL;
@@ -0,0 +1,5 @@
+DELETE FROM notification_outbox WHERE processed_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_notification_outbox_pending;
+
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS processed_at;
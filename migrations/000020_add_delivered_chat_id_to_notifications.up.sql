@@ -0,0 +1,8 @@
+ALTER TABLE notifications
+    ADD COLUMN delivered_chat_id BIGINT NOT NULL DEFAULT 0;
+
+UPDATE notifications
+SET delivered_chat_id = chat_id
+WHERE delivered_via = 'telegram'
+  AND channel = 'telegram'
+  AND NOT fallback @> '[{"channel": "telegram"}]';
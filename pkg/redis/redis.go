@@ -10,8 +10,14 @@ import (
 )
 
 const (
-	StatusCacheTTL    = 24 * time.Hour
-	StatusCachePrefix = "notification:status:"
+	CacheTTL = time.Hour
+	// PendingCacheTTL is used for notifications a worker may change at any
+	// moment, so a copy read just before such a change and cached after it
+	// is dropped was only stale for a short while.
+	PendingCacheTTL = 30 * time.Second
+	CachePrefix     = "notification:record:"
+	TombstoneTTL    = 30 * 24 * time.Hour
+	TombstonePrefix = "notification:cancelled:"
 )
 
 func NewRedisClient(cfg *config.Config, ctx context.Context) (*redis.Client, error) {
@@ -32,6 +38,11 @@ func NewRedisClient(cfg *config.Config, ctx context.Context) (*redis.Client, err
 	return client, nil
 }
 
+// CacheKey is where the serialized notification is cached.
 func CacheKey(id string) string {
-	return StatusCachePrefix + id
+	return CachePrefix + id
+}
+
+func TombstoneKey(id string) string {
+	return TombstonePrefix + id
 }
@@ -1,6 +1,16 @@
+const PAGE_SIZE = 50;
+
+let nextCursor = '';
+let loadedPages = 0;
+
 document.addEventListener('DOMContentLoaded', function() {
     loadNotifications();
-    setInterval(loadNotifications, 5000);
+    // Refreshing would drop the pages loaded with "Load more".
+    setInterval(() => {
+        if (loadedPages <= 1) {
+            loadNotifications();
+        }
+    }, 5000);
 });
 
 document.getElementById('notificationForm').addEventListener('submit', async function(e) {
@@ -44,19 +54,62 @@ document.getElementById('notificationForm').addEventListener('submit', async fun
     }
 });
 
+function notificationsUrl(cursor) {
+    const params = new URLSearchParams({ limit: PAGE_SIZE });
+    const status = document.getElementById('statusFilter').value;
+    if (status) {
+        params.set('status', status);
+    }
+    if (cursor) {
+        params.set('cursor', cursor);
+    }
+    return '/api/v1/notifications?' + params.toString();
+}
+
 async function loadNotifications() {
     try {
-        const response = await fetch('/api/v1/notifications');
-        const notifications = await response.json();
+        const response = await fetch(notificationsUrl(''));
+        const page = await response.json();
 
         const container = document.getElementById('notificationsList');
+        loadedPages = 1;
 
-        if (!notifications || notifications.length === 0) {
+        if (!page.notifications || page.notifications.length === 0) {
             container.innerHTML = '<div class="empty-state">No notifications yet</div>';
+            updatePaging(page, 0);
             return;
         }
 
-        container.innerHTML = notifications.map(nf => `
+        container.innerHTML = page.notifications.map(renderNotification).join('');
+        updatePaging(page, page.notifications.length);
+    } catch (error) {
+        console.error('Failed to load notifications:', error);
+    }
+}
+
+async function loadMoreNotifications() {
+    if (!nextCursor) return;
+    try {
+        const response = await fetch(notificationsUrl(nextCursor));
+        const page = await response.json();
+
+        const container = document.getElementById('notificationsList');
+        container.insertAdjacentHTML('beforeend', page.notifications.map(renderNotification).join(''));
+        loadedPages++;
+        updatePaging(page, container.querySelectorAll('.notification-item').length);
+    } catch (error) {
+        console.error('Failed to load notifications:', error);
+    }
+}
+
+function updatePaging(page, shown) {
+    nextCursor = page.next_cursor || '';
+    document.getElementById('notificationsSummary').textContent = page.total ? `Showing ${shown} of ${page.total}` : '';
+    document.getElementById('loadMoreBtn').style.display = nextCursor ? 'block' : 'none';
+}
+
+function renderNotification(nf) {
+    return `
             <div class="notification-item">
                 <div class="notification-header">
                     <span class="notification-id">ID: ${nf.id}</span>
@@ -70,10 +123,7 @@ async function loadNotifications() {
                     ${nf.time ? `<div class="detail-item"><strong>Send Time:</strong> ${formatTime(nf.time)}</div>` : ''}
                 </div>
             </div>
-        `).join('');
-    } catch (error) {
-        console.error('Failed to load notifications:', error);
-    }
+        `;
 }
 
 function showSuccess(message) {